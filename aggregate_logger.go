@@ -0,0 +1,163 @@
+package negroni
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// aggregateReservoirSize is the number of latencies sampled per path and
+// interval to compute the percentiles.
+const aggregateReservoirSize = 512
+
+// AggregateLogger is a middleware handler logging one summary line per path
+// every interval given to NewAggregateLogger instead of one line per request,
+// for very chatty endpoints. A summary holds the request count and the median
+// and 95th percentile latencies, estimated from a uniform sample of the
+// requests. Paths without requests in an interval are not logged. The
+// summaries are written by a goroutine which Stop, or Close, ends.
+type AggregateLogger struct {
+	// ALogger implements just enough log.Logger interface to be compatible with other implementations
+	ALogger
+	// KeyFunc, if set, returns the key requests are aggregated under instead
+	// of their path, such as the route template they matched, so paths with
+	// IDs do not get a summary each. It is called once the rest of the chain
+	// has returned.
+	KeyFunc func(r *http.Request) string
+
+	clock clock
+
+	mu    sync.Mutex
+	stats map[string]*pathStats
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+type pathStats struct {
+	count     int
+	latencies []time.Duration
+}
+
+// NewAggregateLogger returns a new AggregateLogger instance and starts
+// logging summaries every interval. It panics if interval is not positive.
+func NewAggregateLogger(interval time.Duration) *AggregateLogger {
+	if interval <= 0 {
+		panic("negroni: aggregate logger interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	return newAggregateLogger(ticker.C, ticker.Stop)
+}
+
+// newAggregateLogger returns a new AggregateLogger logging summaries on every
+// tick, and calling stopTick once stopped.
+func newAggregateLogger(tick <-chan time.Time, stopTick func()) *AggregateLogger {
+	a := &AggregateLogger{
+		ALogger: log.New(os.Stdout, "[negroni] ", 0),
+		stats:   make(map[string]*pathStats),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.run(tick, stopTick)
+	return a
+}
+
+// SetNow sets the function used to measure the latencies, time.Now by
+// default.
+func (a *AggregateLogger) SetNow(now func() time.Time) {
+	a.clock = now
+}
+
+func (a *AggregateLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := a.clock.now()
+	next(rw, r)
+	latency := a.clock.since(start)
+
+	key := r.URL.Path
+	if a.KeyFunc != nil {
+		key = a.KeyFunc(r)
+	}
+	a.record(key, latency)
+}
+
+// Stop stops logging summaries, after logging the ones of the current
+// interval.
+func (a *AggregateLogger) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stop)
+		<-a.done
+		a.flush()
+	})
+}
+
+// Close implements io.Closer by calling Stop. It always returns nil.
+func (a *AggregateLogger) Close() error {
+	a.Stop()
+	return nil
+}
+
+func (a *AggregateLogger) run(tick <-chan time.Time, stopTick func()) {
+	defer close(a.done)
+	defer stopTick()
+	for {
+		select {
+		case <-tick:
+			a.flush()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+func (a *AggregateLogger) record(path string, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.stats[path]
+	if !ok {
+		s = &pathStats{}
+		a.stats[path] = s
+	}
+	s.count++
+	// reservoir sampling keeps every request with the same probability
+	if len(s.latencies) < aggregateReservoirSize {
+		s.latencies = append(s.latencies, latency)
+	} else if i := rand.Intn(s.count); i < aggregateReservoirSize {
+		s.latencies[i] = latency
+	}
+}
+
+// flush logs the summaries of the current interval and starts a new one.
+func (a *AggregateLogger) flush() {
+	a.mu.Lock()
+	stats := a.stats
+	a.stats = make(map[string]*pathStats)
+	a.mu.Unlock()
+
+	paths := make([]string, 0, len(stats))
+	for path := range stats {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		s := stats[path]
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+		a.Printf("%s: %d requests | p50 %v | p95 %v", path, s.count, percentile(s.latencies, 50), percentile(s.latencies, 95))
+	}
+}
+
+// percentile returns the p-th percentile of sorted, using the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
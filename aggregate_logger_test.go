@@ -0,0 +1,94 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregateLogger(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	tick := make(chan time.Time)
+	stopped := false
+	a := newAggregateLogger(tick, func() { stopped = true })
+	a.ALogger = log.New(buff, "[negroni] ", 0)
+
+	n := New(a)
+	serve := func(path string) {
+		req, _ := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	for i := 0; i < 3; i++ {
+		serve("/a")
+	}
+	serve("/b")
+	expect(t, buff.String(), "")
+
+	// the second tick is received once the first flush is done
+	tick <- time.Time{}
+	tick <- time.Time{}
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	expect(t, len(lines), 2)
+	expect(t, strings.HasPrefix(lines[0], "[negroni] /a: 3 requests | p50 "), true)
+	expect(t, strings.HasPrefix(lines[1], "[negroni] /b: 1 requests | p50 "), true)
+
+	buff.Reset()
+	serve("/b")
+	expect(t, a.Close(), nil)
+	expect(t, stopped, true)
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] /b: 1 requests | p50 "), true)
+}
+
+func TestNewAggregateLogger_interval(t *testing.T) {
+	defer func() {
+		refute(t, recover(), nil)
+	}()
+	NewAggregateLogger(0)
+}
+
+func TestAggregateLogger_percentiles(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	a := NewAggregateLogger(time.Hour)
+	a.ALogger = log.New(buff, "", 0)
+
+	for i := 1; i <= 100; i++ {
+		a.record("/p", time.Duration(i)*time.Millisecond)
+	}
+	a.Stop()
+	a.Stop()
+	expect(t, buff.String(), "/p: 100 requests | p50 50ms | p95 95ms\n")
+
+	expect(t, percentile(nil, 50), time.Duration(0))
+	expect(t, percentile([]time.Duration{time.Second}, 95), time.Second)
+}
+
+func TestAggregateLogger_keyFunc(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	a := NewAggregateLogger(time.Hour)
+	a.ALogger = log.New(buff, "", 0)
+	a.KeyFunc = func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, "/users/") {
+			return "/users/{id}"
+		}
+		return r.URL.Path
+	}
+	now := time.Unix(0, 0)
+	a.SetNow(func() time.Time { return now })
+
+	n := New(a)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		now = now.Add(10 * time.Millisecond)
+	})
+	for _, path := range []string{"/users/1", "/users/2", "/users/3", "/health"} {
+		req, _ := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	a.Stop()
+
+	expect(t, buff.String(), "/health: 1 requests | p50 10ms | p95 10ms\n"+
+		"/users/{id}: 3 requests | p50 10ms | p95 10ms\n")
+}
@@ -0,0 +1,49 @@
+package negroni
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"runtime"
+)
+
+// AllocStats is a middleware handler for performance investigations that logs
+// the memory allocated while serving a request, when it is at least Threshold
+// bytes. The allocations are measured with runtime.ReadMemStats, which stops
+// the world and counts the allocations of the whole process: only a
+// SampleRate fraction of the requests, between 0 and 1, is measured, and the
+// figures include what concurrent requests allocated meanwhile.
+type AllocStats struct {
+	// ALogger implements just enough log.Logger interface to be compatible with other implementations
+	ALogger
+	SampleRate float64
+	Threshold  uint64
+}
+
+// NewAllocStats returns a new AllocStats instance measuring 1% of the
+// requests and logging those allocating 1MB or more.
+func NewAllocStats() *AllocStats {
+	return &AllocStats{
+		ALogger:    log.New(os.Stdout, "[negroni] ", 0),
+		SampleRate: 0.01,
+		Threshold:  1 << 20,
+	}
+}
+
+func (a *AllocStats) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if a.SampleRate <= 0 || (a.SampleRate < 1 && rand.Float64() >= a.SampleRate) {
+		next(rw, r)
+		return
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	next(rw, r)
+	runtime.ReadMemStats(&after)
+
+	allocated := after.TotalAlloc - before.TotalAlloc
+	if allocated >= a.Threshold {
+		a.Printf("%s %s allocated %d bytes in %d objects", r.Method, r.URL.Path, allocated, after.Mallocs-before.Mallocs)
+	}
+}
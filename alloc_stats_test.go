@@ -0,0 +1,47 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var allocSink []byte
+
+func TestAllocStats(t *testing.T) {
+	var buff bytes.Buffer
+	a := NewAllocStats()
+	a.ALogger = log.New(&buff, "[negroni] ", 0)
+	a.SampleRate = 1
+
+	n := New(a)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		allocSink = make([]byte, 4<<20)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/heavy", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] GET /heavy allocated "), true)
+	expect(t, strings.Contains(buff.String(), " bytes in "), true)
+}
+
+func TestAllocStats_notSampled(t *testing.T) {
+	var buff bytes.Buffer
+	a := NewAllocStats()
+	a.ALogger = log.New(&buff, "[negroni] ", 0)
+	a.SampleRate = 0
+
+	n := New(a)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		allocSink = make([]byte, 4<<20)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/heavy", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, buff.String(), "")
+}
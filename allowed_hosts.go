@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AllowedHosts is a middleware handler that rejects requests whose Host header
+// is not in an allowlist with a 400. Hosts are compared case-insensitively and
+// without port. An entry like "*.example.com" matches any subdomain of
+// example.com, but not example.com itself. An empty allowlist allows all hosts.
+type AllowedHosts struct {
+	Hosts []string
+}
+
+// NewAllowedHosts returns a new instance of AllowedHosts
+func NewAllowedHosts(hosts ...string) *AllowedHosts {
+	return &AllowedHosts{Hosts: hosts}
+}
+
+func (a *AllowedHosts) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if len(a.Hosts) > 0 && !a.allowed(normalizeHost(r.Host)) {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	next(rw, r)
+}
+
+func (a *AllowedHosts) allowed(host string) bool {
+	if host == "" {
+		return false
+	}
+	for _, allowed := range a.Hosts {
+		allowed = normalizeHost(allowed)
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHost lowercases host and strips any port and trailing dot.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,51 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	n := New()
+	n.Use(NewAllowedHosts("example.com", "*.example.org"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for host, code := range map[string]int{
+		"example.com":      http.StatusNoContent,
+		"EXAMPLE.com:8080": http.StatusNoContent,
+		"example.com.":     http.StatusNoContent,
+		"api.example.org":  http.StatusNoContent,
+		"a.b.example.org":  http.StatusNoContent,
+		"example.org":      http.StatusBadRequest,
+		"evilexample.org":  http.StatusBadRequest,
+		"www.example.com":  http.StatusBadRequest,
+		"attacker.com":     http.StatusBadRequest,
+		"example.com.evil": http.StatusBadRequest,
+		"":                 http.StatusBadRequest,
+	} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		req.Host = host
+		n.ServeHTTP(recorder, req)
+		if recorder.Code != code {
+			t.Errorf("Host %q: expected %d, got %d", host, code, recorder.Code)
+		}
+	}
+}
+
+func TestAllowedHosts_empty(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewAllowedHosts())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "http://anything.test/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNoContent)
+}
@@ -0,0 +1,70 @@
+package negroni
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrUnauthorized is returned by verifiers when the request carries missing
+// or invalid credentials.
+var ErrUnauthorized = errors.New("negroni: unauthorized")
+
+var basicAuthUserKey = &contextKey{"basic-auth-user"}
+
+// Auth is a middleware handler that authenticates requests with a pluggable
+// verifier. If the verifier succeeds the next handler is called with the
+// context it returned, otherwise a 401 is written and the chain stops.
+type Auth struct {
+	// Verify authenticates the request and returns the context to continue with.
+	// A nil context keeps the request context unchanged.
+	Verify func(*http.Request) (context.Context, error)
+	// Challenge is the value of the WWW-Authenticate header sent with a 401.
+	Challenge string
+}
+
+// NewAuth returns a new instance of Auth
+func NewAuth(verify func(*http.Request) (context.Context, error)) *Auth {
+	return &Auth{
+		Verify:    verify,
+		Challenge: `Basic realm="Restricted"`,
+	}
+}
+
+func (a *Auth) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx, err := a.Verify(r)
+	if err != nil {
+		rw.Header().Set("WWW-Authenticate", a.Challenge)
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if ctx != nil {
+		r = r.WithContext(ctx)
+	}
+	next(rw, r)
+}
+
+// BasicAuthVerifier returns a verifier for NewAuth checking HTTP basic auth
+// credentials against users, a map of user names to passwords. The
+// authenticated user name can be read with BasicAuthUser.
+func BasicAuthVerifier(users map[string]string) func(*http.Request) (context.Context, error) {
+	return func(r *http.Request) (context.Context, error) {
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+		expected, found := users[user]
+		// compare anyway so unknown users take as long as wrong passwords
+		match := secureCompare(pass, expected)
+		if !found || !match {
+			return nil, ErrUnauthorized
+		}
+		return context.WithValue(r.Context(), basicAuthUserKey, user), nil
+	}
+}
+
+// BasicAuthUser returns the user name stored by BasicAuthVerifier, if any.
+func BasicAuthUser(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(basicAuthUserKey).(string)
+	return user, ok
+}
@@ -0,0 +1,95 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuth(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	called := false
+
+	n := New()
+	n.Use(NewAuth(func(r *http.Request) (context.Context, error) {
+		return nil, nil
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, called, true)
+}
+
+func TestAuth_failure(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	called := false
+
+	n := New()
+	n.Use(NewAuth(func(r *http.Request) (context.Context, error) {
+		return nil, ErrUnauthorized
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusUnauthorized)
+	expect(t, recorder.Header().Get("WWW-Authenticate"), `Basic realm="Restricted"`)
+	expect(t, called, false)
+}
+
+func TestAuth_contextPropagation(t *testing.T) {
+	type key struct{}
+	recorder := httptest.NewRecorder()
+	value := ""
+
+	n := New()
+	n.Use(NewAuth(func(r *http.Request) (context.Context, error) {
+		return context.WithValue(r.Context(), key{}, "enriched"), nil
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		value, _ = r.Context().Value(key{}).(string)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, value, "enriched")
+}
+
+func TestBasicAuthVerifier(t *testing.T) {
+	user := ""
+
+	n := New()
+	n.Use(NewAuth(BasicAuthVerifier(map[string]string{"gopher": "secret"})))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		user, _ = BasicAuthUser(r.Context())
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.SetBasicAuth("gopher", "secret")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, user, "gopher")
+
+	recorder = httptest.NewRecorder()
+	req.SetBasicAuth("gopher", "wrong")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusUnauthorized)
+
+	recorder = httptest.NewRecorder()
+	req.SetBasicAuth("nobody", "secret")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusUnauthorized)
+
+	recorder = httptest.NewRecorder()
+	req.Header.Del("Authorization")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusUnauthorized)
+}
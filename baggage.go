@@ -0,0 +1,76 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var baggageKey = &contextKey{"baggage"}
+
+// Baggage is a middleware handler that parses the W3C baggage header of
+// incoming requests and stores its entries in the request context, see
+// BaggageFromContext. Properties of the entries are ignored. Use
+// InjectBaggage to propagate the entries on outbound requests.
+type Baggage struct{}
+
+// NewBaggage returns a new instance of Baggage
+func NewBaggage() *Baggage {
+	return &Baggage{}
+}
+
+func (b *Baggage) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	values := r.Header["Baggage"]
+	if len(values) == 0 {
+		next(rw, r)
+		return
+	}
+	baggage := parseBaggage(strings.Join(values, ","))
+	next(rw, r.WithContext(context.WithValue(r.Context(), baggageKey, baggage)))
+}
+
+// parseBaggage parses a baggage header value, skipping invalid members.
+func parseBaggage(header string) map[string]string {
+	baggage := make(map[string]string)
+	for _, member := range strings.Split(header, ",") {
+		// drop the properties following the value
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		i := strings.IndexByte(member, '=')
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(member[:i])
+		value, err := url.PathUnescape(strings.TrimSpace(member[i+1:]))
+		if key == "" || err != nil {
+			continue
+		}
+		baggage[key] = value
+	}
+	return baggage
+}
+
+// BaggageFromContext returns the baggage entries stored by Baggage, or nil if
+// there are none. The map must not be modified.
+func BaggageFromContext(ctx context.Context) map[string]string {
+	baggage, _ := ctx.Value(baggageKey).(map[string]string)
+	return baggage
+}
+
+// InjectBaggage sets the baggage header of the outbound request out to the
+// baggage entries stored in ctx. It does nothing if there are none.
+func InjectBaggage(ctx context.Context, out *http.Request) {
+	baggage := BaggageFromContext(ctx)
+	if len(baggage) == 0 {
+		return
+	}
+	members := make([]string, 0, len(baggage))
+	for key, value := range baggage {
+		members = append(members, key+"="+url.PathEscape(value))
+	}
+	sort.Strings(members)
+	out.Header.Set("Baggage", strings.Join(members, ","))
+}
@@ -0,0 +1,60 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBaggage(t *testing.T) {
+	var baggage map[string]string
+
+	n := New()
+	n.Use(NewBaggage())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		baggage = BaggageFromContext(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Add("Baggage", "userId=alice, serverNode = DF%2028 ,isProduction=false;prop=1")
+	req.Header.Add("Baggage", "invalid,tenant=acme")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, len(baggage), 4)
+	expect(t, baggage["userId"], "alice")
+	expect(t, baggage["serverNode"], "DF 28")
+	expect(t, baggage["isProduction"], "false")
+	expect(t, baggage["tenant"], "acme")
+}
+
+func TestBaggage_none(t *testing.T) {
+	called := false
+
+	n := New()
+	n.Use(NewBaggage())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+		expect(t, len(BaggageFromContext(r.Context())), 0)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, called, true)
+}
+
+func TestInjectBaggage(t *testing.T) {
+	var out *http.Request
+
+	n := New()
+	n.Use(NewBaggage())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		out, _ = http.NewRequest("GET", "http://upstream/", nil)
+		InjectBaggage(r.Context(), out)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("Baggage", "userId=alice,serverNode=DF%2028")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, out.Header.Get("Baggage"), "serverNode=DF%2028,userId=alice")
+}
@@ -0,0 +1,90 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// BandwidthLimit is a middleware handler that throttles response bodies to
+// BytesPerSec, to simulate slow clients or spare a shared link. Bodies are
+// written in chunks of a tenth of a second worth of bytes, the first one right
+// away, each flushed so the client sees progress. Writes fail with the context
+// error once the client is gone.
+type BandwidthLimit struct {
+	BytesPerSec int
+}
+
+// NewBandwidthLimit returns a new instance of BandwidthLimit
+func NewBandwidthLimit(bytesPerSec int) *BandwidthLimit {
+	return &BandwidthLimit{BytesPerSec: bytesPerSec}
+}
+
+func (b *BandwidthLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if b.BytesPerSec <= 0 {
+		next(rw, r)
+		return
+	}
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	chunk := b.BytesPerSec / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	next(&bandwidthLimitWriter{ResponseWriter: res, ctx: r.Context(), rate: b.BytesPerSec, chunk: chunk}, r)
+}
+
+type bandwidthLimitWriter struct {
+	ResponseWriter
+	ctx   context.Context
+	rate  int
+	chunk int
+	// start is when the first chunk was written, sent the bytes written since
+	start time.Time
+	sent  int64
+}
+
+func (bw *bandwidthLimitWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		n := bw.chunk
+		if n > len(b) {
+			n = len(b)
+		}
+		if err := bw.wait(); err != nil {
+			return written, err
+		}
+		m, err := bw.ResponseWriter.Write(b[:n])
+		written += m
+		bw.sent += int64(m)
+		if err != nil {
+			return written, err
+		}
+		bw.ResponseWriter.Flush()
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// wait blocks until the bytes sent so far are within the rate.
+func (bw *bandwidthLimitWriter) wait() error {
+	if bw.start.IsZero() {
+		bw.start = time.Now()
+		return nil
+	}
+	due := bw.start.Add(time.Duration(float64(bw.sent) / float64(bw.rate) * float64(time.Second)))
+	d := time.Until(due)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-bw.ctx.Done():
+		return bw.ctx.Err()
+	}
+}
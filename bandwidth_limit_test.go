@@ -0,0 +1,48 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimit(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 5000)
+	n := New(NewBandwidthLimit(10000))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write(body)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	start := time.Now()
+	n.ServeHTTP(recorder, req)
+	elapsed := time.Since(start)
+
+	// the first 1000 bytes are sent right away, the rest at 10000 bytes/s
+	if elapsed < 350*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected the response to take about 400ms, took %v", elapsed)
+	}
+	expect(t, recorder.Body.Len(), len(body))
+	expect(t, recorder.Flushed, true)
+}
+
+func TestBandwidthLimit_clientGone(t *testing.T) {
+	var err error
+	n := New(NewBandwidthLimit(1000))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, err = rw.Write(bytes.Repeat([]byte("x"), 5000))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req.WithContext(ctx))
+
+	expect(t, err, context.DeadlineExceeded)
+	expect(t, recorder.Body.Len(), 200)
+}
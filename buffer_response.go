@@ -0,0 +1,140 @@
+package negroni
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BufferResponse is a middleware handler that buffers response bodies of up
+// to Max bytes so they can be sent with a Content-Length instead of chunked.
+// The status is held back as well, so Before callbacks run once the handler
+// returned. A response outgrowing Max, or explicitly flushed by the handler,
+// is streamed through as it is written.
+type BufferResponse struct {
+	Max int
+}
+
+// NewBufferResponse returns a new instance of BufferResponse
+func NewBufferResponse(maxBuffer int) *BufferResponse {
+	return &BufferResponse{Max: maxBuffer}
+}
+
+func (b *BufferResponse) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	bw := &bufferResponseWriter{ResponseWriter: res, max: b.Max}
+	next(bw, r)
+	bw.finish()
+}
+
+type bufferResponseWriter struct {
+	ResponseWriter
+	max       int
+	status    int
+	buf       bytes.Buffer
+	streaming bool
+}
+
+func (bw *bufferResponseWriter) WriteHeader(s int) {
+	if bw.streaming {
+		bw.ResponseWriter.WriteHeader(s)
+		return
+	}
+	if bw.status == 0 {
+		bw.status = s
+	}
+}
+
+func (bw *bufferResponseWriter) Write(b []byte) (int, error) {
+	if bw.streaming {
+		return bw.ResponseWriter.Write(b)
+	}
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	if bw.buf.Len()+len(b) <= bw.max {
+		return bw.buf.Write(b)
+	}
+	if err := bw.stream(); err != nil {
+		return 0, err
+	}
+	return bw.ResponseWriter.Write(b)
+}
+
+func (bw *bufferResponseWriter) Flush() {
+	if !bw.streaming {
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+		bw.stream()
+	}
+	bw.ResponseWriter.Flush()
+}
+
+func (bw *bufferResponseWriter) Status() int {
+	if bw.streaming {
+		return bw.ResponseWriter.Status()
+	}
+	return bw.status
+}
+
+func (bw *bufferResponseWriter) Written() bool {
+	return bw.Status() != 0
+}
+
+func (bw *bufferResponseWriter) Size() int {
+	return bw.ResponseWriter.Size() + bw.buf.Len()
+}
+
+// stream sends the held back status and buffered body and makes all further
+// writes go straight through.
+func (bw *bufferResponseWriter) stream() error {
+	bw.streaming = true
+	bw.ResponseWriter.WriteHeader(bw.status)
+	_, err := bw.buf.WriteTo(bw.ResponseWriter)
+	return err
+}
+
+// finish sends a response that was fully buffered, with its Content-Length
+// unless it has trailers, which require a chunked body.
+func (bw *bufferResponseWriter) finish() {
+	if bw.streaming || bw.status == 0 {
+		return
+	}
+	if bw.Header().Get("Content-Length") == "" && bodyAllowedForStatus(bw.status) && !hasTrailers(bw.Header()) {
+		bw.Header().Set("Content-Length", strconv.Itoa(bw.buf.Len()))
+	}
+	bw.stream()
+}
+
+// hasTrailers reports whether h declares trailers, in a Trailer header or with
+// keys prefixed by http.TrailerPrefix.
+func hasTrailers(h http.Header) bool {
+	if _, ok := h["Trailer"]; ok {
+		return true
+	}
+	for k := range h {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyAllowedForStatus reports whether a given response status code
+// permits a body. See RFC 7230, section 3.3.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package negroni
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBufferResponse(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var beforeStatus int
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.(ResponseWriter).Before(func(w ResponseWriter) {
+			beforeStatus = w.Status()
+			w.Header().Set("X-Before", "yes")
+		})
+		next(rw, r)
+	})
+	n.Use(NewBufferResponse(16))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("hello "))
+		// nothing is sent until the handler returns
+		expect(t, recorder.Body.Len(), 0)
+		expect(t, rw.(ResponseWriter).Size(), 6)
+		rw.Write([]byte("world"))
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusCreated)
+	expect(t, recorder.Header().Get("Content-Length"), "11")
+	expect(t, recorder.Header().Get("X-Before"), "yes")
+	expect(t, recorder.Body.String(), "hello world")
+	expect(t, beforeStatus, http.StatusCreated)
+}
+
+func TestBufferResponse_overflow(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	body := strings.Repeat("x", 32)
+
+	n := New()
+	n.Use(NewBufferResponse(16))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(body[:10]))
+		expect(t, recorder.Body.Len(), 0)
+		rw.Write([]byte(body[10:]))
+		// streamed as soon as the buffer overflowed
+		expect(t, recorder.Body.Len(), 32)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("Content-Length"), "")
+	expect(t, recorder.Body.String(), body)
+}
+
+func TestBufferResponse_noBody(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewBufferResponse(16))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("Content-Length"), "")
+}
+
+func TestBufferResponse_trailers(t *testing.T) {
+	n := New(NewBufferResponse(64))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Trailer", "Grpc-Status")
+		rw.Write([]byte("body"))
+		rw.Header().Set("Grpc-Status", "0")
+	})
+	server := httptest.NewServer(n)
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	expect(t, err, nil)
+	expect(t, string(body), "body")
+	expect(t, res.ContentLength, int64(-1))
+	expect(t, res.Trailer.Get("Grpc-Status"), "0")
+}
@@ -0,0 +1,52 @@
+package negroni
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CanonicalHost is a middleware handler that redirects requests for any other
+// host than Host to the same scheme, path and query on Host, with Code, such
+// as http.StatusMovedPermanently. Host may include a port. Requests already
+// on Host, compared case-insensitively, pass through. The scheme is https for
+// requests that came over TLS or, if TrustProxy is set, whose
+// X-Forwarded-Proto header says so, so redirects behind a TLS terminating
+// proxy do not downgrade to http. TrustProxy must only be set behind a proxy
+// that sets the header, as clients can send any value.
+type CanonicalHost struct {
+	Host       string
+	Code       int
+	TrustProxy bool
+}
+
+// NewCanonicalHost returns a new instance of CanonicalHost
+func NewCanonicalHost(target string, code int) *CanonicalHost {
+	return &CanonicalHost{Host: target, Code: code}
+}
+
+func (c *CanonicalHost) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if strings.EqualFold(r.Host, c.Host) {
+		next(rw, r)
+		return
+	}
+
+	scheme := "http"
+	if isHTTPS(r, c.TrustProxy) {
+		scheme = "https"
+	}
+	http.Redirect(rw, r, scheme+"://"+c.Host+r.URL.RequestURI(), c.Code)
+}
+
+// isHTTPS reports whether r came over TLS or, if trustProxy is set, whether
+// its X-Forwarded-Proto header says it reached the proxy over HTTPS.
+func isHTTPS(r *http.Request, trustProxy bool) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !trustProxy {
+		return false
+	}
+	// the proxy closest to the client comes first
+	proto := strings.Split(r.Header.Get("X-Forwarded-Proto"), ",")[0]
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
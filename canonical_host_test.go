@@ -0,0 +1,56 @@
+package negroni
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	called := false
+	n := New(NewCanonicalHost("www.example.com", http.StatusMovedPermanently))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://example.com/a/b?q=1&r=2", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusMovedPermanently)
+	expect(t, recorder.Header().Get("Location"), "http://www.example.com/a/b?q=1&r=2")
+	expect(t, called, false)
+
+	recorder = httptest.NewRecorder()
+	req.TLS = &tls.ConnectionState{}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Location"), "https://www.example.com/a/b?q=1&r=2")
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://WWW.example.com/a", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, called, true)
+}
+
+func TestCanonicalHost_trustProxy(t *testing.T) {
+	c := NewCanonicalHost("www.example.com", http.StatusPermanentRedirect)
+	n := New(c)
+
+	req, _ := http.NewRequest("GET", "http://example.com/a", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Location"), "http://www.example.com/a")
+
+	c.TrustProxy = true
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusPermanentRedirect)
+	expect(t, recorder.Header().Get("Location"), "https://www.example.com/a")
+
+	req.Header.Set("X-Forwarded-Proto", "http")
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Location"), "http://www.example.com/a")
+}
@@ -0,0 +1,18 @@
+package negroni
+
+import "time"
+
+// clock tells the time for latency measurements, so tests can control it.
+// A nil clock uses time.Now.
+type clock func() time.Time
+
+func (c clock) now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c()
+}
+
+func (c clock) since(t time.Time) time.Duration {
+	return c.now().Sub(t)
+}
@@ -0,0 +1,41 @@
+package negroni
+
+import (
+	"net/http"
+	"time"
+)
+
+// Conditional is a middleware handler answering conditional GET and HEAD
+// requests based on a last modification time. When LastModified reports a
+// time and the If-Modified-Since request header is not older than it, a 304 is
+// written and the next handler is not called. Otherwise the Last-Modified
+// header is set and the next handler is called.
+type Conditional struct {
+	LastModified func(*http.Request) (time.Time, bool)
+}
+
+// NewConditional returns a new instance of Conditional
+func NewConditional(lastModified func(*http.Request) (time.Time, bool)) *Conditional {
+	return &Conditional{LastModified: lastModified}
+}
+
+func (c *Conditional) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		next(rw, r)
+		return
+	}
+	modtime, ok := c.LastModified(r)
+	if !ok || modtime.IsZero() {
+		next(rw, r)
+		return
+	}
+
+	// Last-Modified has a one second resolution
+	modtime = modtime.UTC().Truncate(time.Second)
+	rw.Header().Set("Last-Modified", modtime.Format(http.TimeFormat))
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modtime.After(since) {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+	next(rw, r)
+}
@@ -0,0 +1,58 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConditional(t *testing.T) {
+	modtime := time.Date(2019, 7, 3, 12, 0, 0, 500, time.UTC)
+	called := false
+
+	n := New()
+	n.Use(NewConditional(func(r *http.Request) (time.Time, bool) {
+		return modtime, r.URL.Path == "/known"
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+		rw.Write([]byte("body"))
+	})
+
+	// not modified since
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/known", nil)
+	req.Header.Set("If-Modified-Since", modtime.Format(http.TimeFormat))
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNotModified)
+	expect(t, recorder.Body.Len(), 0)
+	expect(t, called, false)
+
+	// modified since
+	recorder = httptest.NewRecorder()
+	req.Header.Set("If-Modified-Since", modtime.Add(-time.Hour).Format(http.TimeFormat))
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("Last-Modified"), "Wed, 03 Jul 2019 12:00:00 GMT")
+	expect(t, recorder.Body.String(), "body")
+	expect(t, called, true)
+
+	// no conditional header
+	called = false
+	recorder = httptest.NewRecorder()
+	req.Header.Del("If-Modified-Since")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, called, true)
+
+	// unknown modification time
+	called = false
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/unknown", nil)
+	req.Header.Set("If-Modified-Since", modtime.Format(http.TimeFormat))
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("Last-Modified"), "")
+	expect(t, called, true)
+}
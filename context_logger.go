@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package negroni
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+var contextLoggerKey = &contextKey{"context-logger"}
+
+// ContextLogger is a middleware handler that stores a request scoped
+// *slog.Logger in the request context. The logger is derived from Base with
+// the request ID (taken from the X-Request-Id header), method and path
+// attached, and can be retrieved with LoggerFromContext.
+type ContextLogger struct {
+	Base *slog.Logger
+}
+
+// NewContextLogger returns a new instance of ContextLogger. A nil base uses
+// slog.Default().
+func NewContextLogger(base *slog.Logger) *ContextLogger {
+	return &ContextLogger{Base: base}
+}
+
+func (c *ContextLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	base := c.Base
+	if base == nil {
+		base = slog.Default()
+	}
+	logger := base.With(
+		slog.String("request_id", r.Header.Get("X-Request-Id")),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+	)
+	next(rw, r.WithContext(context.WithValue(r.Context(), contextLoggerKey, logger)))
+}
+
+// LoggerFromContext returns the logger stored by ContextLogger, or
+// slog.Default() if there is none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextLoggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,52 @@
+//go:build go1.21
+// +build go1.21
+
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func init() {
+	contextKeys[contextLoggerKey] = slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestLoggerFromContext_noCollision(t *testing.T) {
+	logger := contextKeys[contextLoggerKey]
+	ctx := context.WithValue(context.Background(), contextLoggerKey, logger)
+	ctx = context.WithValue(ctx, "context-logger", "string value")
+	expect(t, LoggerFromContext(ctx), logger)
+}
+
+func TestContextLogger(t *testing.T) {
+	var buff bytes.Buffer
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewContextLogger(slog.New(slog.NewTextHandler(&buff, nil))))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		LoggerFromContext(r.Context()).Info("handled")
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	req.Header.Set("X-Request-Id", "abc123")
+	n.ServeHTTP(recorder, req)
+
+	line := buff.String()
+	for _, attr := range []string{"msg=handled", "request_id=abc123", "method=GET", "path=/foobar"} {
+		if !strings.Contains(line, attr) {
+			t.Errorf("Expected %q in log line %q", attr, line)
+		}
+	}
+}
+
+func TestLoggerFromContext_default(t *testing.T) {
+	expect(t, LoggerFromContext(context.Background()), slog.Default())
+}
@@ -0,0 +1,71 @@
+package negroni
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+var cspNonceKey = &contextKey{"csp-nonce"}
+
+// CSPNonce is a middleware handler that generates a random nonce per request
+// for a strict Content-Security-Policy. The nonce is stored in the request
+// context, see NonceFromContext, and added as 'nonce-...' to Directive in the
+// Content-Security-Policy response header just before it is written, so the
+// header and the body always use the same nonce. The policy itself is set by
+// the handlers: responses without one, or whose policy lacks Directive, are
+// left as they are, since inventing a directive would change what the policy
+// allows.
+type CSPNonce struct {
+	Directive string
+}
+
+// NewCSPNonce returns a new instance of CSPNonce for the script-src directive.
+func NewCSPNonce() *CSPNonce {
+	return &CSPNonce{Directive: "script-src"}
+}
+
+func (c *CSPNonce) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	nonce := base64.StdEncoding.EncodeToString(b)
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	res.Before(func(ResponseWriter) {
+		h := res.Header()
+		if policy, ok := addNonce(h.Get("Content-Security-Policy"), c.Directive, nonce); ok {
+			h.Set("Content-Security-Policy", policy)
+		}
+	})
+	next(res, r.WithContext(context.WithValue(r.Context(), cspNonceKey, nonce)))
+}
+
+// NonceFromContext returns the nonce generated by CSPNonce for the request the
+// context belongs to, or "" if there is none.
+func NonceFromContext(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceKey).(string)
+	return nonce
+}
+
+// addNonce adds the nonce source to directive in policy, reporting false if
+// policy has no such directive.
+func addNonce(policy, directive, nonce string) (string, bool) {
+	source := "'nonce-" + nonce + "'"
+	directives := strings.Split(policy, ";")
+	for i, d := range directives {
+		fields := strings.Fields(d)
+		if len(fields) > 0 && strings.EqualFold(fields[0], directive) {
+			directives[i] = strings.TrimRight(d, " ") + " " + source
+			return strings.Join(directives, ";"), true
+		}
+	}
+	return policy, false
+}
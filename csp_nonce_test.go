@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSPNonce(t *testing.T) {
+	var nonce string
+	n := New(NewCSPNonce())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		nonce = NonceFromContext(r.Context())
+		rw.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self'; img-src *")
+		rw.Write([]byte(`<script nonce="` + nonce + `"></script>`))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	refute(t, nonce, "")
+	expect(t, recorder.Header().Get("Content-Security-Policy"), "default-src 'self'; script-src 'self' 'nonce-"+nonce+"'; img-src *")
+	expect(t, strings.Contains(recorder.Body.String(), nonce), true)
+
+	previous := nonce
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	refute(t, nonce, previous)
+}
+
+func TestCSPNonce_missingDirective(t *testing.T) {
+	c := NewCSPNonce()
+	c.Directive = "style-src"
+	n := New(c)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		refute(t, NonceFromContext(r.Context()), "")
+		rw.Header().Set("Content-Security-Policy", "default-src 'self';")
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Content-Security-Policy"), "default-src 'self';")
+
+	// no policy is made up either
+	recorder = httptest.NewRecorder()
+	New(c, WrapFunc(http.NotFound)).ServeHTTP(recorder, req)
+	_, ok := recorder.Header()["Content-Security-Policy"]
+	expect(t, ok, false)
+	expect(t, NonceFromContext(req.Context()), "")
+}
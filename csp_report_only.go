@@ -0,0 +1,75 @@
+package negroni
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// cspReportMaxSize is the largest violation report CSPReportHandler accepts.
+const cspReportMaxSize = 64 << 10
+
+// CSPReportOnly is a middleware handler that sets Policy as the
+// Content-Security-Policy-Report-Only header of responses that do not have
+// one, so violations are reported, e.g. to a CSPReportHandler, without being
+// blocked. It helps rolling out a policy before enforcing it.
+type CSPReportOnly struct {
+	Policy string
+}
+
+// NewCSPReportOnly returns a new instance of CSPReportOnly
+func NewCSPReportOnly(policy string) *CSPReportOnly {
+	return &CSPReportOnly{Policy: policy}
+}
+
+func (c *CSPReportOnly) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	res.Before(func(w ResponseWriter) {
+		if w.Header().Get("Content-Security-Policy-Report-Only") == "" {
+			w.Header().Set("Content-Security-Policy-Report-Only", c.Policy)
+		}
+	})
+	next(res, r)
+}
+
+// CSPReportHandler returns a handler receiving the violation reports browsers
+// POST to the report-uri of a policy, as application/csp-report or
+// application/reports+json. Every report that is valid JSON is passed to sink
+// and answered with a 204. Other methods get a 405, other content types a 415
+// and reports larger than 64KB a 413.
+func CSPReportHandler(sink func(report []byte)) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			rw.Header().Set("Allow", "POST")
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mt != "application/csp-report" && mt != "application/reports+json" {
+			http.Error(rw, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		report, err := ioutil.ReadAll(io.LimitReader(r.Body, cspReportMaxSize+1))
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if len(report) > cspReportMaxSize {
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if !json.Valid(report) {
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		sink(report)
+		rw.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,77 @@
+package negroni
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSPReportOnly(t *testing.T) {
+	n := New(NewCSPReportOnly("default-src 'self'; report-uri /csp-report"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("page"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Header().Get("Content-Security-Policy-Report-Only"), "default-src 'self'; report-uri /csp-report")
+	expect(t, recorder.Header().Get("Content-Security-Policy"), "")
+}
+
+const sampleCSPReport = `{
+	"csp-report": {
+		"document-uri": "https://example.com/page",
+		"violated-directive": "script-src 'self'",
+		"blocked-uri": "https://evil.example.com/x.js"
+	}
+}`
+
+func TestCSPReportHandler(t *testing.T) {
+	var reports [][]byte
+	handler := CSPReportHandler(func(report []byte) {
+		reports = append(reports, report)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/csp-report", strings.NewReader(sampleCSPReport))
+	req.Header.Set("Content-Type", "application/csp-report")
+	handler.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, len(reports), 1)
+	var report struct {
+		CSPReport struct {
+			ViolatedDirective string `json:"violated-directive"`
+			BlockedURI        string `json:"blocked-uri"`
+		} `json:"csp-report"`
+	}
+	expect(t, json.Unmarshal(reports[0], &report), nil)
+	expect(t, report.CSPReport.ViolatedDirective, "script-src 'self'")
+	expect(t, report.CSPReport.BlockedURI, "https://evil.example.com/x.js")
+}
+
+func TestCSPReportHandler_rejected(t *testing.T) {
+	handler := CSPReportHandler(func(report []byte) {
+		t.Error("no report should reach the sink")
+	})
+
+	for _, tt := range []struct {
+		method, contentType, body string
+		status                    int
+	}{
+		{"GET", "application/csp-report", "", http.StatusMethodNotAllowed},
+		{"POST", "text/plain", sampleCSPReport, http.StatusUnsupportedMediaType},
+		{"POST", "application/csp-report", "{not json", http.StatusBadRequest},
+		{"POST", "application/csp-report", strings.Repeat(" ", cspReportMaxSize+1), http.StatusRequestEntityTooLarge},
+	} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, "http://localhost:3000/csp-report", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		handler.ServeHTTP(recorder, req)
+		expect(t, recorder.Code, tt.status)
+	}
+}
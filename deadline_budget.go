@@ -0,0 +1,63 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DeadlineBudget is a middleware handler that applies the deadline of a call
+// chain to the request context, so that it is propagated downstream. The
+// deadline is read from the X-Deadline header, an absolute RFC 3339 time, and
+// is at most Max from now if Max is positive. Requests with a missing or
+// invalid header get a deadline of Default from now, none if Default is zero.
+// Handlers can pass the remaining budget, see RemainingBudget, to their own
+// outbound calls. Requests that ran past the deadline are flagged to a
+// Logger served before DeadlineBudget, see LoggerEntry.TimedOut.
+type DeadlineBudget struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// NewDeadlineBudget returns a new instance of DeadlineBudget without a Max.
+func NewDeadlineBudget(fallback time.Duration) *DeadlineBudget {
+	return &DeadlineBudget{Default: fallback}
+}
+
+func (b *DeadlineBudget) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	deadline, ok := b.deadline(r.Header.Get("X-Deadline"), time.Now())
+	if !ok {
+		next(rw, r)
+		return
+	}
+
+	ctx, cancel := context.WithDeadline(r.Context(), deadline)
+	defer cancel()
+	r = r.WithContext(ctx)
+	next(rw, r)
+	markTimedOut(rw, r)
+}
+
+func (b *DeadlineBudget) deadline(value string, now time.Time) (time.Time, bool) {
+	deadline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if b.Default <= 0 {
+			return time.Time{}, false
+		}
+		deadline = now.Add(b.Default)
+	}
+	if b.Max > 0 && deadline.Sub(now) > b.Max {
+		deadline = now.Add(b.Max)
+	}
+	return deadline, true
+}
+
+// RemainingBudget returns the time left before the deadline of ctx, or false
+// if it has none. The result is negative once the deadline passed.
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
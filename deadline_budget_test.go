@@ -0,0 +1,56 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadlineBudget_deadline(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	b := NewDeadlineBudget(5 * time.Second)
+
+	deadline, ok := b.deadline("2020-01-02T03:04:35Z", now)
+	expect(t, ok, true)
+	expect(t, deadline.Sub(now), 30*time.Second)
+
+	deadline, ok = b.deadline("2020-01-02T05:04:35+02:00", now)
+	expect(t, ok, true)
+	expect(t, deadline.Sub(now), 30*time.Second)
+
+	deadline, ok = b.deadline("soon", now)
+	expect(t, ok, true)
+	expect(t, deadline.Sub(now), 5*time.Second)
+
+	b.Max = 10 * time.Second
+	deadline, _ = b.deadline("2020-01-02T03:04:35Z", now)
+	expect(t, deadline.Sub(now), 10*time.Second)
+
+	b.Default = 0
+	_, ok = b.deadline("", now)
+	expect(t, ok, false)
+}
+
+func TestDeadlineBudget(t *testing.T) {
+	var remaining time.Duration
+	var hasBudget bool
+	n := New(NewDeadlineBudget(time.Minute))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		remaining, hasBudget = RemainingBudget(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("X-Deadline", time.Now().Add(10*time.Second).Format(time.RFC3339))
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, hasBudget, true)
+	expect(t, remaining > 8*time.Second && remaining <= 10*time.Second, true)
+
+	req.Header.Del("X-Deadline")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, remaining > 59*time.Second && remaining <= time.Minute, true)
+
+	_, hasBudget = RemainingBudget(context.Background())
+	expect(t, hasBudget, false)
+}
@@ -0,0 +1,56 @@
+// Package debug serves the pprof and expvar debug endpoints from a Negroni
+// chain when enabled. It is a separate package because importing
+// net/http/pprof and expvar registers their handlers on
+// http.DefaultServeMux, which Negroni itself must not do.
+package debug
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// DebugRoutes is a middleware handler serving /debug/pprof/ and /debug/vars
+// with the net/http/pprof and expvar handlers when Enabled, without calling
+// the next handler. When disabled those paths get a 404, so they are never
+// served by the rest of the chain either. Other paths are passed through.
+type DebugRoutes struct {
+	Enabled bool
+}
+
+// NewDebugRoutes returns a new instance of DebugRoutes, typically enabled by
+// a command line flag.
+func NewDebugRoutes(enabled bool) *DebugRoutes {
+	return &DebugRoutes{Enabled: enabled}
+}
+
+func (d *DebugRoutes) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	path := r.URL.Path
+	if path != "/debug/vars" && path != "/debug/pprof" && !strings.HasPrefix(path, "/debug/pprof/") {
+		next(rw, r)
+		return
+	}
+	if !d.Enabled {
+		http.NotFound(rw, r)
+		return
+	}
+
+	switch path {
+	case "/debug/vars":
+		expvar.Handler().ServeHTTP(rw, r)
+	case "/debug/pprof":
+		http.Redirect(rw, r, "/debug/pprof/", http.StatusMovedPermanently)
+	case "/debug/pprof/cmdline":
+		pprof.Cmdline(rw, r)
+	case "/debug/pprof/profile":
+		pprof.Profile(rw, r)
+	case "/debug/pprof/symbol":
+		pprof.Symbol(rw, r)
+	case "/debug/pprof/trace":
+		pprof.Trace(rw, r)
+	default:
+		// the index also serves the named profiles, such as heap
+		pprof.Index(rw, r)
+	}
+}
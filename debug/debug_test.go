@@ -0,0 +1,69 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/urfave/negroni"
+)
+
+func serve(enabled bool, path string) (*httptest.ResponseRecorder, bool) {
+	called := false
+	n := negroni.New(NewDebugRoutes(enabled))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+	n.ServeHTTP(recorder, req)
+	return recorder, called
+}
+
+func TestDebugRoutes_enabled(t *testing.T) {
+	for path, content := range map[string]string{
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile",
+		"/debug/pprof/cmdline":           "debug.test",
+		"/debug/vars":                    `"memstats"`,
+		"/debug/pprof/heap?debug=1":      "heap profile",
+	} {
+		recorder, called := serve(true, path)
+		if called {
+			t.Errorf("%s: the next handler should not be called", path)
+		}
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", path, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), content) {
+			t.Errorf("%s: expected %q in the body", path, content)
+		}
+	}
+
+	recorder, _ := serve(true, "/debug/pprof")
+	if recorder.Code != http.StatusMovedPermanently {
+		t.Errorf("expected a redirect to the index, got %d", recorder.Code)
+	}
+}
+
+func TestDebugRoutes_disabled(t *testing.T) {
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/vars"} {
+		recorder, called := serve(false, path)
+		if called {
+			t.Errorf("%s: the next handler should not be called", path)
+		}
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status 404, got %d", path, recorder.Code)
+		}
+	}
+}
+
+func TestDebugRoutes_otherPaths(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		if _, called := serve(enabled, "/debug/other"); !called {
+			t.Errorf("enabled=%v: the next handler should be called", enabled)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package negroni
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DigestVerify is a middleware handler that checks the integrity of request
+// bodies against the digests sent by the client, in a Digest header (RFC
+// 3230) with the sha-256 or md5 algorithm, or in a Content-MD5 header, all
+// base64 encoded. Requests with a digest that does not match get a 400, and
+// bodies larger than MaxBodySize get a 413. Requests without a supported
+// digest pass through, unless Required is set, in which case they get a 400.
+// The body is buffered, so it is still readable by the next handler.
+type DigestVerify struct {
+	MaxBodySize int64
+	Required    bool
+}
+
+// NewDigestVerify returns a new instance of DigestVerify accepting bodies up
+// to 1MB.
+func NewDigestVerify() *DigestVerify {
+	return &DigestVerify{MaxBodySize: 1 << 20}
+}
+
+func (v *DigestVerify) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	digests := requestDigests(r.Header)
+	if len(digests) == 0 {
+		if v.Required {
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		next(rw, r)
+		return
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(io.LimitReader(r.Body, v.MaxBodySize+1))
+		r.Body.Close()
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > v.MaxBodySize {
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+
+	for alg, expected := range digests {
+		var sum []byte
+		switch alg {
+		case "sha-256":
+			s := sha256.Sum256(body)
+			sum = s[:]
+		case "md5":
+			s := md5.Sum(body)
+			sum = s[:]
+		}
+		if subtle.ConstantTimeCompare(sum, expected) != 1 {
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	next(rw, r)
+}
+
+// requestDigests returns the decoded digests of the supported algorithms in
+// h. A digest that is not valid base64 is kept empty, so it never matches.
+func requestDigests(h http.Header) map[string][]byte {
+	digests := make(map[string][]byte)
+	for _, header := range h["Digest"] {
+		for _, d := range strings.Split(header, ",") {
+			i := strings.IndexByte(d, '=')
+			if i < 0 {
+				continue
+			}
+			alg := strings.ToLower(strings.TrimSpace(d[:i]))
+			if alg != "sha-256" && alg != "md5" {
+				continue
+			}
+			digests[alg], _ = base64.StdEncoding.DecodeString(strings.TrimSpace(d[i+1:]))
+		}
+	}
+	if md5sum := h.Get("Content-Md5"); md5sum != "" {
+		sum, _ := base64.StdEncoding.DecodeString(md5sum)
+		if prev, ok := digests["md5"]; ok && !bytes.Equal(prev, sum) {
+			// conflicting digests
+			sum = nil
+		}
+		digests["md5"] = sum
+	}
+	return digests
+}
@@ -0,0 +1,65 @@
+package negroni
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDigestVerify(t *testing.T) {
+	const payload = `{"event":"push"}`
+	sha := sha256.Sum256([]byte(payload))
+	md := md5.Sum([]byte(payload))
+	shaDigest := base64.StdEncoding.EncodeToString(sha[:])
+	mdDigest := base64.StdEncoding.EncodeToString(md[:])
+
+	for _, tt := range []struct {
+		header, value string
+		status        int
+	}{
+		{"Digest", "sha-256=" + shaDigest, http.StatusOK},
+		{"Digest", "SHA-256=" + shaDigest + ", md5=" + mdDigest, http.StatusOK},
+		{"Digest", "unixsum=30637", http.StatusOK},
+		{"Content-MD5", mdDigest, http.StatusOK},
+		{"Digest", "sha-256=" + mdDigest, http.StatusBadRequest},
+		{"Digest", "sha-256=" + shaDigest + ", md5=" + shaDigest, http.StatusBadRequest},
+		{"Digest", "sha-256=not base64!", http.StatusBadRequest},
+		{"Content-MD5", shaDigest, http.StatusBadRequest},
+	} {
+		var body string
+		n := New(NewDigestVerify())
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+		})
+
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "http://localhost:3000/hook", strings.NewReader(payload))
+		req.Header.Set(tt.header, tt.value)
+		n.ServeHTTP(recorder, req)
+
+		expect(t, recorder.Code, tt.status)
+		if tt.status == http.StatusOK {
+			expect(t, body, payload)
+		} else {
+			expect(t, body, "")
+		}
+	}
+}
+
+func TestDigestVerify_required(t *testing.T) {
+	v := NewDigestVerify()
+	v.Required = true
+	n := New(v)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/hook", strings.NewReader("payload"))
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,63 @@
+package negroni
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DrainGuard is a middleware handler tracking in-flight requests so they can
+// be drained before the server stops. Once Drain is called, new requests get a
+// 503 and the next handler is not called.
+type DrainGuard struct {
+	// Timeout is how long Drain waits for in-flight requests.
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// NewDrainGuard returns a new instance of DrainGuard, waiting at most
+// DefaultShutdownTimeout, along with a function calling its Drain method.
+func NewDrainGuard() (*DrainGuard, func()) {
+	g := &DrainGuard{Timeout: DefaultShutdownTimeout}
+	return g, func() { g.Drain() }
+}
+
+func (g *DrainGuard) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	// Add must not race with Wait, so it happens under the lock Drain takes
+	g.mu.Lock()
+	if g.draining {
+		g.mu.Unlock()
+		rw.Header().Set("Connection", "close")
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	g.inflight.Add(1)
+	g.mu.Unlock()
+
+	defer g.inflight.Done()
+	next(rw, r)
+}
+
+// Drain stops accepting new requests and blocks until the in-flight ones
+// finished or Timeout elapsed. It reports whether they all finished.
+func (g *DrainGuard) Drain() bool {
+	g.mu.Lock()
+	g.draining = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(g.Timeout):
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrainGuard(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	finished := false
+
+	g, drain := NewDrainGuard()
+	n := New(g)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		finished = true
+	})
+
+	go func() {
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-started
+
+	drained := make(chan struct{})
+	go func() {
+		drain()
+		close(drained)
+	}()
+
+	// wait for draining to kick in, new requests are now refused
+	time.Sleep(20 * time.Millisecond)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+
+	select {
+	case <-drained:
+		t.Fatal("Expected drain to wait for the in-flight request")
+	default:
+	}
+
+	close(release)
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("Expected drain to return once the in-flight request completed")
+	}
+	expect(t, finished, true)
+}
+
+func TestDrainGuard_timeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	g, _ := NewDrainGuard()
+	g.Timeout = 10 * time.Millisecond
+	n := New(g)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	go func() {
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-started
+
+	expect(t, g.Drain(), false)
+}
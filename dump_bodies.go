@@ -0,0 +1,94 @@
+package negroni
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+// DumpBodies is a middleware handler that logs up to Max bytes of the request
+// and response bodies, for debugging. The request body is captured as the
+// handler reads it, so the handler still gets all of it. Redact, if set, is
+// applied to both dumps before they are logged, to mask sensitive fields.
+//
+// It does nothing unless Debug is set, which NewDumpBodies does when the
+// NEGRONI_ENV environment variable is EnvDevelopment.
+type DumpBodies struct {
+	Max    int
+	Debug  bool
+	Redact func(body []byte) []byte
+	Logger ALogger
+}
+
+// NewDumpBodies returns a new instance of DumpBodies
+func NewDumpBodies(max int) *DumpBodies {
+	return &DumpBodies{
+		Max:    max,
+		Debug:  os.Getenv("NEGRONI_ENV") == EnvDevelopment,
+		Logger: log.New(os.Stdout, "[negroni] ", 0),
+	}
+}
+
+func (d *DumpBodies) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !d.Debug {
+		next(rw, r)
+		return
+	}
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+
+	reqBody := &cappedBuffer{max: d.Max}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = &replayBody{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+	}
+	dw := &dumpResponseWriter{ResponseWriter: res, body: cappedBuffer{max: d.Max}}
+	next(dw, r)
+
+	d.Logger.Printf("%s %s request body: %s", r.Method, r.URL.Path, d.dump(reqBody))
+	d.Logger.Printf("%s %s response body: %s", r.Method, r.URL.Path, d.dump(&dw.body))
+}
+
+func (d *DumpBodies) dump(b *cappedBuffer) string {
+	body := b.Bytes()
+	if d.Redact != nil {
+		body = d.Redact(body)
+	}
+	if b.truncated {
+		return string(body) + "... (truncated)"
+	}
+	return string(body)
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.max - b.Len(); len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+type dumpResponseWriter struct {
+	ResponseWriter
+	body cappedBuffer
+}
+
+func (dw *dumpResponseWriter) Write(b []byte) (int, error) {
+	n, err := dw.ResponseWriter.Write(b)
+	dw.body.Write(b[:n])
+	return n, err
+}
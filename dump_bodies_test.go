@@ -0,0 +1,60 @@
+package negroni
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDumpBodies(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	var received string
+
+	d := NewDumpBodies(8)
+	d.Debug = true
+	d.Logger = log.New(buff, "[negroni] ", 0)
+	d.Redact = func(body []byte) []byte {
+		return bytes.Replace(body, []byte("secret"), []byte("******"), -1)
+	}
+
+	n := New(d)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+		rw.Write([]byte("ok"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/login", strings.NewReader("secret=hunter2&user=bob"))
+	n.ServeHTTP(recorder, req)
+
+	expect(t, received, "secret=hunter2&user=bob")
+	expect(t, recorder.Body.String(), "ok")
+	expect(t, buff.String(), "[negroni] POST /login request body: ******=h... (truncated)\n"+
+		"[negroni] POST /login response body: ok\n")
+}
+
+func TestDumpBodies_disabled(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	d := NewDumpBodies(8)
+	d.Debug = false
+	d.Logger = log.New(buff, "[negroni] ", 0)
+
+	var body interface{}
+	n := New(d)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body = r.Body
+		rw.Write([]byte("ok"))
+	})
+
+	reqBody := ioutil.NopCloser(strings.NewReader("payload"))
+	req, _ := http.NewRequest("POST", "http://localhost:3000/", reqBody)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, buff.Len(), 0)
+	expect(t, body, interface{}(reqBody))
+}
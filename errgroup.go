@@ -0,0 +1,37 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var errGroupKey = &contextKey{"errgroup"}
+
+// ErrGroup is a middleware handler that gives every request an errgroup.Group
+// to fan out concurrent work, see ErrGroupFromContext. The request context
+// passed to the next handler is the context of the group: it is cancelled when
+// a sub-task fails and when the rest of the chain returns, after which the
+// sub-tasks still running are waited for.
+type ErrGroup struct{}
+
+// NewErrGroup returns a new instance of ErrGroup
+func NewErrGroup() *ErrGroup {
+	return &ErrGroup{}
+}
+
+func (e *ErrGroup) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	g, ctx := errgroup.WithContext(ctx)
+	next(rw, r.WithContext(context.WithValue(ctx, errGroupKey, g)))
+	cancel()
+	g.Wait()
+}
+
+// ErrGroupFromContext returns the errgroup.Group of the request the context
+// belongs to, or nil if it is not served by ErrGroup.
+func ErrGroupFromContext(ctx context.Context) *errgroup.Group {
+	g, _ := ctx.Value(errGroupKey).(*errgroup.Group)
+	return g
+}
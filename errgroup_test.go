@@ -0,0 +1,59 @@
+package negroni
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func TestErrGroup(t *testing.T) {
+	cancelled := make(chan error, 1)
+	n := New(NewErrGroup())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		g := ErrGroupFromContext(r.Context())
+		refute(t, g, nil)
+		ctx := r.Context()
+		g.Go(func() error {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil
+		})
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	// the sub-task was waited for, so its result is already there
+	select {
+	case err := <-cancelled:
+		expect(t, err, context.Canceled)
+	default:
+		t.Fatal("Expected the sub-task to be cancelled when the request completed")
+	}
+}
+
+func TestErrGroup_failure(t *testing.T) {
+	var err error
+	n := New(NewErrGroup())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		g := ErrGroupFromContext(r.Context())
+		g.Go(func() error {
+			return errors.New("failed")
+		})
+		g.Go(func() error {
+			<-r.Context().Done()
+			return nil
+		})
+		err = g.Wait()
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, err.Error(), "failed")
+	expect(t, ErrGroupFromContext(context.Background()), (*errgroup.Group)(nil))
+}
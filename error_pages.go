@@ -0,0 +1,99 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ErrorPages is a middleware handler that serves custom pages, such as branded
+// 404 or 500 pages, for responses whose status is in Pages and that have no
+// body. The status of those responses is held back until the handler writes a
+// body, which is sent untouched, or returns, in which case the page is sent
+// with ContentType.
+type ErrorPages struct {
+	Pages       map[int][]byte
+	ContentType string
+}
+
+// NewErrorPages returns a new instance of ErrorPages serving HTML pages
+func NewErrorPages(pages map[int][]byte) *ErrorPages {
+	return &ErrorPages{Pages: pages, ContentType: "text/html; charset=utf-8"}
+}
+
+func (e *ErrorPages) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	ew := &errorPageWriter{ResponseWriter: res, pages: e.Pages}
+	next(ew, r)
+
+	if ew.pending == 0 {
+		return
+	}
+	page := e.Pages[ew.pending]
+	h := res.Header()
+	h.Set("Content-Type", e.ContentType)
+	h.Set("Content-Length", strconv.Itoa(len(page)))
+	res.WriteHeader(ew.pending)
+	res.Write(page)
+}
+
+type errorPageWriter struct {
+	ResponseWriter
+	pages map[int][]byte
+	// pending is the held back status while it may get a page
+	pending int
+}
+
+func (ew *errorPageWriter) WriteHeader(code int) {
+	if ew.Written() {
+		ew.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if _, ok := ew.pages[code]; ok {
+		ew.pending = code
+		return
+	}
+	ew.ResponseWriter.WriteHeader(code)
+}
+
+func (ew *errorPageWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if !ew.Written() {
+		ew.WriteHeader(http.StatusOK)
+	}
+	ew.release()
+	return ew.ResponseWriter.Write(b)
+}
+
+func (ew *errorPageWriter) Flush() {
+	if !ew.Written() {
+		ew.WriteHeader(http.StatusOK)
+	}
+	// a flushed response is being streamed, its body is not ours to replace
+	ew.release()
+	ew.ResponseWriter.Flush()
+}
+
+func (ew *errorPageWriter) Status() int {
+	if ew.pending != 0 {
+		return ew.pending
+	}
+	return ew.ResponseWriter.Status()
+}
+
+func (ew *errorPageWriter) Written() bool {
+	return ew.Status() != 0
+}
+
+// release sends the held back status, the response having a body of its own.
+func (ew *errorPageWriter) release() {
+	if ew.pending != 0 {
+		status := ew.pending
+		ew.pending = 0
+		ew.ResponseWriter.WriteHeader(status)
+	}
+}
@@ -0,0 +1,59 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorPages(t *testing.T) {
+	n := New(NewErrorPages(map[int][]byte{
+		http.StatusNotFound: []byte("<h1>Lost?</h1>"),
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/missing", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusNotFound)
+	expect(t, recorder.Body.String(), "<h1>Lost?</h1>")
+	expect(t, recorder.Header().Get("Content-Type"), "text/html; charset=utf-8")
+	expect(t, recorder.Header().Get("Content-Length"), "14")
+}
+
+func TestErrorPages_withBody(t *testing.T) {
+	n := New(NewErrorPages(map[int][]byte{
+		http.StatusNotFound: []byte("<h1>Lost?</h1>"),
+	}))
+	n.UseHandler(http.NotFoundHandler())
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/missing", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusNotFound)
+	expect(t, recorder.Body.String(), "404 page not found\n")
+	expect(t, recorder.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+}
+
+func TestErrorPages_otherStatus(t *testing.T) {
+	n := New(NewErrorPages(map[int][]byte{
+		http.StatusNotFound: []byte("<h1>Lost?</h1>"),
+	}))
+	var status int
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+		status = rw.(ResponseWriter).Status()
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, status, http.StatusInternalServerError)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, recorder.Body.String(), "")
+}
@@ -0,0 +1,58 @@
+package negroni
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FaviconPath is the path served by Favicon.
+const FaviconPath = "/favicon.ico"
+
+// Favicon is a middleware handler that serves Data for GET and HEAD requests
+// to FaviconPath, with long lived cache headers, without calling the next
+// handler. Browsers request it all the time, so this keeps it out of the logs
+// and away from the rest of the chain.
+type Favicon struct {
+	Data        []byte
+	ContentType string
+	MaxAge      time.Duration
+}
+
+// NewFavicon returns a new instance of Favicon cached for a year.
+func NewFavicon(data []byte, contentType string) *Favicon {
+	return &Favicon{
+		Data:        data,
+		ContentType: contentType,
+		MaxAge:      365 * 24 * time.Hour,
+	}
+}
+
+// NewFaviconFS returns a new instance of Favicon serving the file name of fs,
+// which is read once, with the icon content type.
+func NewFaviconFS(fs http.FileSystem, name string) (*Favicon, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewFavicon(data, "image/x-icon"), nil
+}
+
+func (f *Favicon) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.URL.Path != FaviconPath || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		next(rw, r)
+		return
+	}
+
+	rw.Header().Set("Content-Type", f.ContentType)
+	rw.Header().Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(f.MaxAge/time.Second), 10))
+	http.ServeContent(rw, r, FaviconPath, time.Time{}, bytes.NewReader(f.Data))
+}
@@ -0,0 +1,41 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFavicon(t *testing.T) {
+	called := false
+	n := New(NewFavicon([]byte("icon"), "image/png"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/favicon.ico", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "icon")
+	expect(t, recorder.Header().Get("Content-Type"), "image/png")
+	expect(t, recorder.Header().Get("Cache-Control"), "public, max-age=31536000")
+	expect(t, called, false)
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/other", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Body.Len(), 0)
+	expect(t, called, true)
+}
+
+func TestNewFaviconFS(t *testing.T) {
+	f, err := NewFaviconFS(http.Dir("."), "/negroni.go")
+	expect(t, err, nil)
+	expect(t, f.ContentType, "image/x-icon")
+	refute(t, len(f.Data), 0)
+
+	f, err = NewFaviconFS(http.Dir("."), "/missing.ico")
+	refute(t, err, nil)
+	expect(t, f, (*Favicon)(nil))
+}
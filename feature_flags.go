@@ -0,0 +1,33 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+)
+
+var featureFlagsKey = &contextKey{"feature-flags"}
+
+// FeatureFlags is a middleware handler resolving the feature flags of each
+// request once, with Resolve, and storing them in the request context so
+// handlers can check them with FlagEnabled.
+type FeatureFlags struct {
+	Resolve func(*http.Request) map[string]bool
+}
+
+// NewFeatureFlags returns a new instance of FeatureFlags
+func NewFeatureFlags(resolve func(*http.Request) map[string]bool) *FeatureFlags {
+	return &FeatureFlags{Resolve: resolve}
+}
+
+func (f *FeatureFlags) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	flags := f.Resolve(r)
+	next(rw, r.WithContext(context.WithValue(r.Context(), featureFlagsKey, flags)))
+}
+
+// FlagEnabled reports whether the flag name was resolved as enabled by
+// FeatureFlags for the request the context belongs to. Unknown flags are
+// disabled.
+func FlagEnabled(ctx context.Context, name string) bool {
+	flags, _ := ctx.Value(featureFlagsKey).(map[string]bool)
+	return flags[name]
+}
@@ -0,0 +1,36 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	resolved := 0
+	n := New(NewFeatureFlags(func(r *http.Request) map[string]bool {
+		resolved++
+		return map[string]bool{
+			"new-checkout": r.Header.Get("X-Beta") == "1",
+			"dark-mode":    true,
+		}
+	}))
+	var checkout, darkMode, unknown bool
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		checkout = FlagEnabled(r.Context(), "new-checkout")
+		darkMode = FlagEnabled(r.Context(), "dark-mode")
+		unknown = FlagEnabled(r.Context(), "unknown")
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("X-Beta", "1")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, resolved, 1)
+	expect(t, checkout, true)
+	expect(t, darkMode, true)
+	expect(t, unknown, false)
+
+	expect(t, FlagEnabled(context.Background(), "dark-mode"), false)
+}
@@ -1,3 +1,5 @@
 module github.com/urfave/negroni
 
 go 1.12
+
+require golang.org/x/sync v0.1.0
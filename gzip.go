@@ -0,0 +1,109 @@
+package negroni
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// Gzip is a middleware handler that compresses response bodies with gzip for
+// clients accepting it, with a non-zero q-value for gzip, or for "*" when
+// gzip is not listed. The response is streamed: a Flush from the handler
+// flushes the compressed data written so far to the client, so Server-Sent
+// Events and other streaming responses stay responsive. Responses that set
+// their own Content-Encoding are left alone.
+type Gzip struct {
+	Level int
+}
+
+// NewGzip returns a new instance of Gzip compressing at level, such as
+// gzip.DefaultCompression.
+func NewGzip(level int) *Gzip {
+	return &Gzip{Level: level}
+}
+
+func (g *Gzip) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rw.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		next(rw, r)
+		return
+	}
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	gw := &gzipResponseWriter{ResponseWriter: res, level: g.Level}
+	defer gw.close()
+	next(gw, r)
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		switch strings.ToLower(strings.TrimSpace(fields[0])) {
+		case "gzip", "x-gzip":
+			return qValue(fields[1:]) > 0
+		case "*":
+			wildcard = qValue(fields[1:]) > 0
+		}
+	}
+	return wildcard
+}
+
+type gzipResponseWriter struct {
+	ResponseWriter
+	level int
+	gz    *gzip.Writer
+}
+
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if code < http.StatusOK || gw.Written() {
+		gw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	h := gw.Header()
+	if h.Get("Content-Encoding") == "" && bodyAllowedForStatus(code) {
+		gz, err := gzip.NewWriterLevel(gw.ResponseWriter, gw.level)
+		if err == nil {
+			gw.gz = gz
+			h.Set("Content-Encoding", "gzip")
+			h.Del("Content-Length")
+		}
+	}
+	gw.ResponseWriter.WriteHeader(code)
+}
+
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.Written() {
+		if gw.Header().Get("Content-Type") == "" {
+			// net/http would sniff the compressed bytes
+			gw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.gz == nil {
+		return gw.ResponseWriter.Write(b)
+	}
+	return gw.gz.Write(b)
+}
+
+// Flush sends the data compressed so far to the client.
+func (gw *gzipResponseWriter) Flush() {
+	if !gw.Written() {
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	gw.ResponseWriter.Flush()
+}
+
+func (gw *gzipResponseWriter) close() {
+	if gw.gz != nil {
+		gw.gz.Close()
+	}
+}
@@ -0,0 +1,102 @@
+package negroni
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	n := New(NewGzip(gzip.DefaultCompression))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", "100")
+		rw.Write([]byte(strings.Repeat("compressible ", 100)))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Header().Get("Content-Encoding"), "gzip")
+	expect(t, recorder.Header().Get("Content-Length"), "")
+	expect(t, recorder.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+	expect(t, recorder.Header().Get("Vary"), "Accept-Encoding")
+
+	zr, err := gzip.NewReader(recorder.Body)
+	expect(t, err, nil)
+	body, err := ioutil.ReadAll(zr)
+	expect(t, err, nil)
+	expect(t, string(body), strings.Repeat("compressible ", 100))
+}
+
+func TestGzip_notAccepted(t *testing.T) {
+	n := New(NewGzip(gzip.DefaultCompression))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("plain"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Content-Encoding"), "")
+	expect(t, recorder.Body.String(), "plain")
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, expected := range map[string]bool{
+		"":                   false,
+		"gzip":               true,
+		"deflate, gzip;q=.5": true,
+		"GZIP":               true,
+		"x-gzip":             true,
+		"gzip;q=0":           false,
+		"gzip; q=0.0, br":    false,
+		"*":                  true,
+		"*;q=0":              false,
+		"gzip;q=0, *":        false,
+		"*, gzip;q=0":        false,
+		"br, deflate":        false,
+		"nogzip":             false,
+	} {
+		if acceptsGzip(header) != expected {
+			t.Errorf("Expected acceptsGzip(%q) to be %v", header, expected)
+		}
+	}
+}
+
+func TestGzip_flush(t *testing.T) {
+	release := make(chan struct{})
+	n := New(NewGzip(gzip.BestSpeed))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.Write([]byte("data: first\n\n"))
+		rw.(http.Flusher).Flush()
+		<-release
+		rw.Write([]byte("data: second\n\n"))
+	})
+	server := httptest.NewServer(n)
+	defer server.Close()
+	defer close(release)
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	// set explicitly so the transport does not decompress
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	expect(t, res.Header.Get("Content-Encoding"), "gzip")
+
+	// the first event can be decompressed while the handler is still blocked
+	zr, err := gzip.NewReader(res.Body)
+	expect(t, err, nil)
+	line, err := bufio.NewReader(zr).ReadString('\n')
+	expect(t, err, nil)
+	expect(t, line, "data: first\n")
+}
@@ -0,0 +1,91 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// HeadOptimizer is a middleware handler that lets handlers serve HEAD requests
+// the same way as GET. Bytes written for a HEAD request are counted but
+// discarded, and the count is sent as Content-Length once the handler returns
+// unless the handler set one itself. The count is also added to the Size of
+// the writers returned by NewResponseWriter, so middleware served before, like
+// Logger, reports the size a GET would have had. The response header is held
+// back until then, so Flush is a no-op for HEAD requests.
+type HeadOptimizer struct{}
+
+// NewHeadOptimizer returns a new instance of HeadOptimizer
+func NewHeadOptimizer() *HeadOptimizer {
+	return &HeadOptimizer{}
+}
+
+func (h *HeadOptimizer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method != "HEAD" {
+		next(rw, r)
+		return
+	}
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	hw := &headResponseWriter{ResponseWriter: res}
+	next(hw, r)
+	hw.finish()
+}
+
+// sizeCounter is implemented by writers returned by NewResponseWriter, for
+// HeadOptimizer to count the body it discards in their Size.
+type sizeCounter interface {
+	countSize(n int)
+}
+
+// headResponseWriter records the status and body size written by the handler
+// without sending either to the wrapped ResponseWriter.
+type headResponseWriter struct {
+	ResponseWriter
+	status int
+	size   int
+}
+
+func (hw *headResponseWriter) WriteHeader(s int) {
+	if hw.status == 0 {
+		hw.status = s
+	}
+}
+
+func (hw *headResponseWriter) Write(b []byte) (int, error) {
+	if hw.status == 0 {
+		hw.status = http.StatusOK
+	}
+	hw.size += len(b)
+	return len(b), nil
+}
+
+func (hw *headResponseWriter) Flush() {}
+
+func (hw *headResponseWriter) Status() int {
+	return hw.status
+}
+
+func (hw *headResponseWriter) Size() int {
+	return hw.size
+}
+
+func (hw *headResponseWriter) Written() bool {
+	return hw.status != 0
+}
+
+// finish sends the recorded status with the computed Content-Length.
+func (hw *headResponseWriter) finish() {
+	if hw.status == 0 {
+		return
+	}
+	if hw.size > 0 && hw.Header().Get("Content-Length") == "" {
+		hw.Header().Set("Content-Length", strconv.Itoa(hw.size))
+	}
+	hw.ResponseWriter.WriteHeader(hw.status)
+	if c, ok := hw.ResponseWriter.(sizeCounter); ok {
+		c.countSize(hw.size)
+	}
+}
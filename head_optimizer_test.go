@@ -0,0 +1,54 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeadOptimizer(t *testing.T) {
+	var size int
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+		size = rw.(ResponseWriter).Size()
+	})
+	n.Use(NewHeadOptimizer())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("hello "))
+		rw.Write([]byte("world"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("HEAD", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("Content-Length"), "11")
+	expect(t, recorder.Body.Len(), 0)
+	// middleware served before sees the discarded body
+	expect(t, size, 11)
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "hello world")
+	expect(t, size, 11)
+}
+
+func TestHeadOptimizer_keepsStatusAndLength(t *testing.T) {
+	n := New()
+	n.Use(NewHeadOptimizer())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", "42")
+		rw.WriteHeader(http.StatusPartialContent)
+		rw.Write([]byte("partial"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("HEAD", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusPartialContent)
+	expect(t, recorder.Header().Get("Content-Length"), "42")
+	expect(t, recorder.Body.Len(), 0)
+}
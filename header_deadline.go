@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// HeaderDeadline is a middleware handler that applies a deadline to the
+// request context from the timeout a client advertises in a header, such as
+// "X-Request-Timeout: 5s". The value is parsed with time.ParseDuration and
+// clamped to Max. A missing, invalid or non-positive value falls back to Max.
+// A Max of zero means no cap and no deadline for requests without the header.
+// Requests that ran past the deadline are flagged to a Logger served before
+// HeaderDeadline, see LoggerEntry.TimedOut.
+type HeaderDeadline struct {
+	Header string
+	Max    time.Duration
+}
+
+// NewHeaderDeadline returns a new instance of HeaderDeadline reading the
+// X-Request-Timeout header.
+func NewHeaderDeadline(maxCap time.Duration) *HeaderDeadline {
+	return &HeaderDeadline{
+		Header: "X-Request-Timeout",
+		Max:    maxCap,
+	}
+}
+
+func (h *HeaderDeadline) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	timeout := h.timeout(r.Header.Get(h.Header))
+	if timeout <= 0 {
+		next(rw, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+	next(rw, r)
+	markTimedOut(rw, r)
+}
+
+func (h *HeaderDeadline) timeout(value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return h.Max
+	}
+	if h.Max > 0 && d > h.Max {
+		return h.Max
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeaderDeadline_timeout(t *testing.T) {
+	h := NewHeaderDeadline(10 * time.Second)
+	for value, timeout := range map[string]time.Duration{
+		"5s":    5 * time.Second,
+		"250ms": 250 * time.Millisecond,
+		"1m":    10 * time.Second,
+		"":      10 * time.Second,
+		"soon":  10 * time.Second,
+		"-1s":   10 * time.Second,
+	} {
+		expect(t, h.timeout(value), timeout)
+	}
+
+	h.Max = 0
+	expect(t, h.timeout("1m"), time.Minute)
+	expect(t, h.timeout(""), time.Duration(0))
+}
+
+func TestHeaderDeadline(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+
+	n := New()
+	n.Use(NewHeaderDeadline(10 * time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		deadline, hasDeadline = r.Context().Deadline()
+		remaining = time.Until(deadline)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("X-Request-Timeout", "2s")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, hasDeadline, true)
+	if remaining > 2*time.Second || remaining < time.Second {
+		t.Errorf("Expected a deadline about 2s away, got %v", remaining)
+	}
+}
+
+func TestHeaderDeadline_none(t *testing.T) {
+	hasDeadline := true
+
+	n := New()
+	n.Use(NewHeaderDeadline(0))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, hasDeadline = r.Context().Deadline()
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, hasDeadline, false)
+}
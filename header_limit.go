@@ -0,0 +1,33 @@
+package negroni
+
+import (
+	"net/http"
+)
+
+// HeaderLimit is a middleware handler that rejects requests whose headers are
+// larger than Max bytes with a 431 (Request Header Fields Too Large), without
+// calling the next handler. The size is the sum of the lengths of every
+// header name and value. Unlike http.Server.MaxHeaderBytes it can be applied
+// to part of an application only.
+type HeaderLimit struct {
+	Max int
+}
+
+// NewHeaderLimit returns a new instance of HeaderLimit
+func NewHeaderLimit(maxBytes int) *HeaderLimit {
+	return &HeaderLimit{Max: maxBytes}
+}
+
+func (h *HeaderLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	size := 0
+	for name, values := range r.Header {
+		for _, value := range values {
+			size += len(name) + len(value)
+		}
+	}
+	if size > h.Max {
+		http.Error(rw, http.StatusText(http.StatusRequestHeaderFieldsTooLarge), http.StatusRequestHeaderFieldsTooLarge)
+		return
+	}
+	next(rw, r)
+}
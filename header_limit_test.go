@@ -0,0 +1,33 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHeaderLimit(t *testing.T) {
+	called := false
+
+	n := New()
+	n.Use(NewHeaderLimit(64))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("X-Small", "value")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, called, true)
+
+	called = false
+	recorder = httptest.NewRecorder()
+	req.Header.Add("X-Large", strings.Repeat("a", 40))
+	req.Header.Add("X-Large", strings.Repeat("b", 40))
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusRequestHeaderFieldsTooLarge)
+	expect(t, called, false)
+}
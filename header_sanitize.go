@@ -0,0 +1,41 @@
+package negroni
+
+import "net/http"
+
+// HeaderSanitize is a middleware handler that normalizes request headers
+// before the next handler parses them. The headers listed in Collapse are
+// reduced to their first value, the ones in CollapseLast to their last value,
+// and the ones in Strip are removed, so a request with conflicting values,
+// such as two Content-Types, reaches the handlers with an unambiguous one.
+type HeaderSanitize struct {
+	Collapse     []string
+	CollapseLast []string
+	Strip        []string
+}
+
+// NewHeaderSanitize returns a new instance of HeaderSanitize collapsing the
+// Content-Type, Authorization, User-Agent and Referer headers to their first
+// value.
+func NewHeaderSanitize() *HeaderSanitize {
+	return &HeaderSanitize{
+		Collapse: []string{"Content-Type", "Authorization", "User-Agent", "Referer"},
+	}
+}
+
+func (s *HeaderSanitize) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	h := r.Header
+	for _, name := range s.Collapse {
+		if v := h[http.CanonicalHeaderKey(name)]; len(v) > 1 {
+			h.Set(name, v[0])
+		}
+	}
+	for _, name := range s.CollapseLast {
+		if v := h[http.CanonicalHeaderKey(name)]; len(v) > 1 {
+			h.Set(name, v[len(v)-1])
+		}
+	}
+	for _, name := range s.Strip {
+		h.Del(name)
+	}
+	next(rw, r)
+}
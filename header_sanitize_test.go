@@ -0,0 +1,37 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderSanitize(t *testing.T) {
+	s := NewHeaderSanitize()
+	s.CollapseLast = []string{"X-Forwarded-Proto"}
+	s.Strip = []string{"X-Internal-Token"}
+
+	var header http.Header
+	n := New(s)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	})
+
+	req, _ := http.NewRequest("POST", "http://localhost:3000/", nil)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", "text/plain")
+	req.Header.Add("X-Forwarded-Proto", "http")
+	req.Header.Add("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Internal-Token", "secret")
+	req.Header.Add("Accept", "text/html")
+	req.Header.Add("Accept", "application/json")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, len(header["Content-Type"]), 1)
+	expect(t, header.Get("Content-Type"), "application/json")
+	expect(t, len(header["X-Forwarded-Proto"]), 1)
+	expect(t, header.Get("X-Forwarded-Proto"), "https")
+	_, ok := header["X-Internal-Token"]
+	expect(t, ok, false)
+	expect(t, len(header["Accept"]), 2)
+}
@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"net/http"
+)
+
+// DefaultHealthCheckPath is the path served by HealthCheck if none is given.
+const DefaultHealthCheckPath = "/healthz"
+
+// HealthCheck is a middleware handler that answers requests for Path with a
+// 200 "ok" without calling the next handler, so it should be placed before
+// heavier middleware. If Ready is set and returns false, a 503 is written
+// instead. Requests for other paths are passed along.
+type HealthCheck struct {
+	Path  string
+	Ready func() bool
+}
+
+// NewHealthCheck returns a new instance of HealthCheck. An empty path uses
+// DefaultHealthCheckPath.
+func NewHealthCheck(path string) *HealthCheck {
+	if path == "" {
+		path = DefaultHealthCheckPath
+	}
+	return &HealthCheck{Path: path}
+}
+
+func (h *HealthCheck) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.URL.Path != h.Path {
+		next(rw, r)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	if h.Ready != nil && !h.Ready() {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte("not ready"))
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte("ok"))
+}
@@ -0,0 +1,49 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	called := false
+
+	n := New()
+	n.Use(NewHealthCheck(""))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/healthz", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "ok")
+	expect(t, called, false)
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/other", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusTeapot)
+	expect(t, called, true)
+}
+
+func TestHealthCheck_notReady(t *testing.T) {
+	ready := false
+	h := NewHealthCheck("/ready")
+	h.Ready = func() bool { return ready }
+
+	n := New(h)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/ready", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+
+	ready = true
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+}
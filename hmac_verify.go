@@ -0,0 +1,65 @@
+package negroni
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// HMACVerify is a middleware handler that verifies signed requests, such as
+// webhooks. The Header must hold the hex encoded HMAC-SHA256 of the request
+// body keyed with Secret, optionally prefixed with "sha256=". Requests with a
+// missing or wrong signature get a 401, and bodies larger than MaxBodySize get
+// a 413. The body is buffered, so it is still readable by the next handler.
+type HMACVerify struct {
+	Secret      []byte
+	Header      string
+	MaxBodySize int64
+}
+
+// NewHMACVerify returns a new instance of HMACVerify accepting bodies up to
+// 1MB.
+func NewHMACVerify(secret []byte, header string) *HMACVerify {
+	return &HMACVerify{
+		Secret:      secret,
+		Header:      header,
+		MaxBodySize: 1 << 20,
+	}
+}
+
+func (v *HMACVerify) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	signature, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(v.Header), "sha256="))
+	if err != nil || len(signature) == 0 {
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = ioutil.ReadAll(io.LimitReader(r.Body, v.MaxBodySize+1))
+		r.Body.Close()
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > v.MaxBodySize {
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	next(rw, r)
+}
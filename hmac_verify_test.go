@@ -0,0 +1,61 @@
+package negroni
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHMACVerify(t *testing.T) {
+	var received string
+	v := NewHMACVerify([]byte("secret"), "X-Signature")
+	n := New(v)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+	})
+
+	for _, tt := range []struct {
+		body      string
+		signature string
+		code      int
+	}{
+		{`{"event":"push"}`, sign("secret", `{"event":"push"}`), http.StatusOK},
+		{`{"event":"push"}`, "sha256=" + sign("secret", `{"event":"push"}`), http.StatusOK},
+		{`{"event":"delete"}`, sign("secret", `{"event":"push"}`), http.StatusUnauthorized},
+		{`{"event":"push"}`, sign("other", `{"event":"push"}`), http.StatusUnauthorized},
+		{`{"event":"push"}`, "not hex", http.StatusUnauthorized},
+		{`{"event":"push"}`, "", http.StatusUnauthorized},
+	} {
+		received = ""
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "http://localhost:3000/hook", strings.NewReader(tt.body))
+		req.Header.Set("X-Signature", tt.signature)
+		n.ServeHTTP(recorder, req)
+
+		expect(t, recorder.Code, tt.code)
+		if tt.code == http.StatusOK {
+			expect(t, received, tt.body)
+		} else {
+			expect(t, received, "")
+		}
+	}
+
+	v.MaxBodySize = 4
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/hook", strings.NewReader("too large"))
+	req.Header.Set("X-Signature", sign("secret", "too large"))
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+}
@@ -0,0 +1,58 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HSTS is a middleware handler that sets the Strict-Transport-Security header
+// on responses to HTTPS requests that do not have one, with MaxAge in seconds
+// and the includeSubDomains and preload directives if IncludeSubDomains and
+// Preload are set. Inclusion in the browsers' preload lists also requires a
+// MaxAge of at least a year and IncludeSubDomains. The header is never sent
+// over plain HTTP, so local development servers keep working. A request is
+// HTTPS if it came over TLS or, if TrustProxy is set, if the X-Forwarded-Proto
+// header says so. TrustProxy must only be set behind a proxy that sets the
+// header, as clients can send any value.
+type HSTS struct {
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	Preload           bool
+	TrustProxy        bool
+}
+
+// NewHSTS returns a new instance of HSTS
+func NewHSTS(maxAge time.Duration, includeSubDomains, preload bool) *HSTS {
+	return &HSTS{MaxAge: maxAge, IncludeSubDomains: includeSubDomains, Preload: preload}
+}
+
+func (h *HSTS) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !isHTTPS(r, h.TrustProxy) {
+		next(rw, r)
+		return
+	}
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	value := h.value()
+	res.Before(func(w ResponseWriter) {
+		if w.Header().Get("Strict-Transport-Security") == "" {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+	})
+	next(res, r)
+}
+
+func (h *HSTS) value() string {
+	value := "max-age=" + strconv.FormatInt(int64(h.MaxAge/time.Second), 10)
+	if h.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+	if h.Preload {
+		value += "; preload"
+	}
+	return value
+}
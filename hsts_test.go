@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHSTS(t *testing.T) {
+	n := New(NewHSTS(365*24*time.Hour, true, true))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("secure"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "https://localhost:3000/", nil)
+	req.TLS = &tls.ConnectionState{}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Strict-Transport-Security"), "max-age=31536000; includeSubDomains; preload")
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Strict-Transport-Security"), "")
+	expect(t, recorder.Body.String(), "secure")
+}
+
+func TestHSTS_forwardedProto(t *testing.T) {
+	h := NewHSTS(time.Hour, false, false)
+	n := New(h)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Strict-Transport-Security"), "")
+
+	h.TrustProxy = true
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Strict-Transport-Security"), "max-age=3600")
+
+	req.Header.Set("X-Forwarded-Proto", "http")
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Strict-Transport-Security"), "")
+}
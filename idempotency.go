@@ -0,0 +1,137 @@
+package negroni
+
+import (
+	"net/http"
+	"sync"
+)
+
+// IdempotentResponse is a response recorded by Idempotency.
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore records the responses of Idempotency keys.
+type IdempotencyStore interface {
+	// Get returns the response recorded for key, if any.
+	Get(key string) (*IdempotentResponse, bool)
+	// Set records the response for key.
+	Set(key string, res *IdempotentResponse)
+	// Lock marks key as in flight. It returns false if it already is,
+	// otherwise a function to release it.
+	Lock(key string) (unlock func(), ok bool)
+}
+
+// Idempotency is a middleware handler that makes unsafe requests carrying an
+// Idempotency-Key header idempotent. The response to the first request with a
+// key is recorded in Store and replayed for later requests with the same key,
+// without calling the next handler again. Requests reusing a key whose first
+// request is still being served get a 409. Server errors (5xx) are not
+// recorded so the request can be retried.
+type Idempotency struct {
+	Store IdempotencyStore
+}
+
+// NewIdempotency returns a new instance of Idempotency
+func NewIdempotency(store IdempotencyStore) *Idempotency {
+	return &Idempotency{Store: store}
+}
+
+func (i *Idempotency) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" || isSafeMethod(r.Method) {
+		next(rw, r)
+		return
+	}
+
+	if res, ok := i.Store.Get(key); ok {
+		replayIdempotentResponse(rw, res)
+		return
+	}
+	unlock, ok := i.Store.Lock(key)
+	if !ok {
+		http.Error(rw, http.StatusText(http.StatusConflict), http.StatusConflict)
+		return
+	}
+	defer unlock()
+	// the first request may have completed between Get and Lock
+	if res, ok := i.Store.Get(key); ok {
+		replayIdempotentResponse(rw, res)
+		return
+	}
+
+	buf := newResponseBuffer()
+	next(NewResponseWriter(buf), r)
+	if buf.status < http.StatusInternalServerError {
+		i.Store.Set(key, &IdempotentResponse{
+			Status: buf.status,
+			Header: buf.header,
+			Body:   buf.body.Bytes(),
+		})
+	}
+	buf.writeTo(rw)
+}
+
+func replayIdempotentResponse(rw http.ResponseWriter, res *IdempotentResponse) {
+	buf := &responseBuffer{header: res.Header, status: res.Status}
+	buf.body.Write(res.Body)
+	rw.Header().Set("Idempotent-Replayed", "true")
+	buf.writeTo(rw)
+}
+
+// isSafeMethod reports whether method is safe as defined by RFC 7231.
+func isSafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+	return false
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore keeping responses in memory.
+// Responses are never evicted, so it is mostly useful for tests and small
+// deployments.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	responses map[string]*IdempotentResponse
+	locked    map[string]bool
+}
+
+// NewMemoryIdempotencyStore returns a new, empty MemoryIdempotencyStore
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		responses: make(map[string]*IdempotentResponse),
+		locked:    make(map[string]bool),
+	}
+}
+
+// Get implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotentResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, ok := s.responses[key]
+	return res, ok
+}
+
+// Set implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Set(key string, res *IdempotentResponse) {
+	s.mu.Lock()
+	s.responses[key] = res
+	s.mu.Unlock()
+}
+
+// Lock implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Lock(key string) (func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locked[key] {
+		return nil, false
+	}
+	s.locked[key] = true
+	return func() {
+		s.mu.Lock()
+		delete(s.locked, key)
+		s.mu.Unlock()
+	}, true
+}
@@ -0,0 +1,83 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+
+	n := New()
+	n.Use(NewIdempotency(NewMemoryIdempotencyStore()))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.Header().Set("X-Charge", "ch_1")
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("charged"))
+	})
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "http://localhost:3000/charges", strings.NewReader("amount=10"))
+		req.Header.Set("Idempotency-Key", "abc")
+		n.ServeHTTP(recorder, req)
+
+		expect(t, recorder.Code, http.StatusCreated)
+		expect(t, recorder.Header().Get("X-Charge"), "ch_1")
+		expect(t, recorder.Body.String(), "charged")
+		expect(t, recorder.Header().Get("Idempotent-Replayed") == "true", i > 0)
+	}
+	expect(t, calls, 1)
+
+	// without a key, or for safe methods, requests always go through
+	req, _ := http.NewRequest("POST", "http://localhost:3000/charges", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("GET", "http://localhost:3000/charges", nil)
+	req.Header.Set("Idempotency-Key", "abc")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, calls, 3)
+}
+
+func TestIdempotency_inFlight(t *testing.T) {
+	var conflict *httptest.ResponseRecorder
+
+	n := New()
+	n.Use(NewIdempotency(NewMemoryIdempotencyStore()))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if conflict != nil {
+			return
+		}
+		// a retry arriving while the first request is still being served
+		conflict = httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "http://localhost:3000/charges", nil)
+		req.Header.Set("Idempotency-Key", "abc")
+		n.ServeHTTP(conflict, req)
+	})
+
+	req, _ := http.NewRequest("POST", "http://localhost:3000/charges", nil)
+	req.Header.Set("Idempotency-Key", "abc")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, conflict.Code, http.StatusConflict)
+}
+
+func TestIdempotency_serverError(t *testing.T) {
+	calls := 0
+
+	n := New()
+	n.Use(NewIdempotency(NewMemoryIdempotencyStore()))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "http://localhost:3000/charges", nil)
+		req.Header.Set("Idempotency-Key", "abc")
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	expect(t, calls, 2)
+}
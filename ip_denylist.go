@@ -0,0 +1,94 @@
+package negroni
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// IPDenylist is a middleware handler that rejects requests from denied
+// networks with a 403 and logs them. The client IP is the host of
+// RemoteAddr or, if TrustProxy is set, the address appended to the
+// X-Forwarded-For header by the outermost of the ProxyHops proxies in front
+// of the server, 1 if unset. Addresses further left are sent by the client
+// and cannot be trusted. TrustProxy must only be set behind proxies that
+// append to the header.
+type IPDenylist struct {
+	Networks   []*net.IPNet
+	TrustProxy bool
+	ProxyHops  int
+	Logger     ALogger
+}
+
+// NewIPDenylist returns a new instance of IPDenylist denying the given
+// networks, in CIDR notation such as "192.0.2.0/24". A plain IP denies that
+// address only. An error is returned if one of them cannot be parsed.
+func NewIPDenylist(cidrs ...string) (*IPDenylist, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return &IPDenylist{
+		Networks: networks,
+		Logger:   log.New(os.Stdout, "[negroni] ", 0),
+	}, nil
+}
+
+func (d *IPDenylist) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	hops := 0
+	if d.TrustProxy {
+		hops = d.ProxyHops
+		if hops < 1 {
+			hops = 1
+		}
+	}
+	ip := clientIP(r, hops)
+	if ip != nil {
+		for _, network := range d.Networks {
+			if network.Contains(ip) {
+				d.Logger.Printf("denied %s %s from %s", r.Method, r.URL.Path, ip)
+				http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+		}
+	}
+	next(rw, r)
+}
+
+// clientIP returns the IP address of the client of r, or nil if it is
+// unknown. With hops trusted proxies in front of the server, it is the
+// X-Forwarded-For address appended by the outermost one, the hops-th from the
+// right, and the host of RemoteAddr without proxies or if the header has fewer
+// addresses.
+func clientIP(r *http.Request, hops int) net.IP {
+	if hops > 0 {
+		var forwarded []string
+		for _, header := range r.Header["X-Forwarded-For"] {
+			forwarded = append(forwarded, strings.Split(header, ",")...)
+		}
+		if len(forwarded) >= hops {
+			return net.ParseIP(strings.TrimSpace(forwarded[len(forwarded)-hops]))
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
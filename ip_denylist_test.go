@@ -0,0 +1,94 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIPDenylist(t *testing.T) {
+	d, err := NewIPDenylist("192.0.2.0/24", "2001:db8::/32", "198.51.100.7")
+	expect(t, err, nil)
+	buff := bytes.NewBufferString("")
+	d.Logger = log.New(buff, "[negroni] ", 0)
+
+	n := New(d)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for remoteAddr, code := range map[string]int{
+		"192.0.2.10:1234":    http.StatusForbidden,
+		"[2001:db8::1]:1234": http.StatusForbidden,
+		"198.51.100.7:1234":  http.StatusForbidden,
+		"198.51.100.8:1234":  http.StatusNoContent,
+		"203.0.113.1:1234":   http.StatusNoContent,
+		"garbage":            http.StatusNoContent,
+	} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000/admin", nil)
+		req.RemoteAddr = remoteAddr
+		n.ServeHTTP(recorder, req)
+		if recorder.Code != code {
+			t.Errorf("Expected %d for %s, got %d", code, remoteAddr, recorder.Code)
+		}
+	}
+	expect(t, strings.Count(buff.String(), "\n"), 3)
+	expect(t, strings.Contains(buff.String(), "[negroni] denied GET /admin from 192.0.2.10"), true)
+}
+
+func TestIPDenylist_trustProxy(t *testing.T) {
+	d, _ := NewIPDenylist("192.0.2.0/24")
+	d.Logger = log.New(bytes.NewBufferString(""), "", 0)
+	n := New(d)
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "192.0.2.10")
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+
+	d.TrustProxy = true
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusForbidden)
+}
+
+func TestIPDenylist_spoofedForwardedFor(t *testing.T) {
+	d, _ := NewIPDenylist("192.0.2.0/24")
+	d.Logger = log.New(bytes.NewBufferString(""), "", 0)
+	d.TrustProxy = true
+	n := New(d)
+
+	// the denied client sends its own header, the proxy appends its address
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.5, 192.0.2.10")
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusForbidden)
+
+	// the same with two proxies, the inner one appending the outer's address
+	d.ProxyHops = 2
+	req.Header.Set("X-Forwarded-For", "203.0.113.5, 192.0.2.10, 10.0.0.2")
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusForbidden)
+
+	req.Header.Set("X-Forwarded-For", "203.0.113.5")
+	req.Header.Add("X-Forwarded-For", "192.0.2.10, 10.0.0.2")
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusForbidden)
+}
+
+func TestNewIPDenylist_invalid(t *testing.T) {
+	d, err := NewIPDenylist("192.0.2.0/24", "192.0.2.0/33")
+	refute(t, err, nil)
+	expect(t, d, (*IPDenylist)(nil))
+}
@@ -0,0 +1,46 @@
+package negroni
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// JitteredCache is a middleware handler that sets "Cache-Control: max-age=N"
+// on responses, with N drawn per response from Base plus or minus up to
+// Jitter, so cached copies do not all expire at once. Responses that already
+// have a Cache-Control header are left alone.
+type JitteredCache struct {
+	Base   time.Duration
+	Jitter time.Duration
+}
+
+// NewJitteredCache returns a new instance of JitteredCache
+func NewJitteredCache(base, jitter time.Duration) *JitteredCache {
+	return &JitteredCache{Base: base, Jitter: jitter}
+}
+
+func (c *JitteredCache) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	res.Before(func(ResponseWriter) {
+		if res.Header().Get("Cache-Control") == "" {
+			res.Header().Set("Cache-Control", "max-age="+strconv.FormatInt(int64(c.maxAge()/time.Second), 10))
+		}
+	})
+	next(res, r)
+}
+
+func (c *JitteredCache) maxAge() time.Duration {
+	age := c.Base
+	if c.Jitter > 0 {
+		age += time.Duration(rand.Int63n(2*int64(c.Jitter)+1)) - c.Jitter
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
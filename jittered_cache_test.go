@@ -0,0 +1,59 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJitteredCache(t *testing.T) {
+	n := New(NewJitteredCache(time.Minute, 10*time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("cached"))
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	seen := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, req)
+
+		header := recorder.Header().Get("Cache-Control")
+		expect(t, strings.HasPrefix(header, "max-age="), true)
+		age, err := strconv.Atoi(strings.TrimPrefix(header, "max-age="))
+		expect(t, err, nil)
+		if age < 50 || age > 70 {
+			t.Errorf("Expected max-age within [50, 70], got %d", age)
+		}
+		seen[age] = true
+	}
+	expect(t, len(seen) > 1, true)
+}
+
+func TestJitteredCache_existingHeader(t *testing.T) {
+	n := New(NewJitteredCache(time.Minute, time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Cache-Control"), "no-store")
+}
+
+func TestJitteredCache_maxAge(t *testing.T) {
+	c := NewJitteredCache(time.Second, 0)
+	expect(t, c.maxAge(), time.Second)
+
+	c = NewJitteredCache(0, time.Hour)
+	for i := 0; i < 100; i++ {
+		if c.maxAge() < 0 {
+			t.Fatal("Expected max-age to never be negative")
+		}
+	}
+}
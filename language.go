@@ -0,0 +1,112 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var languageKey = &contextKey{"language"}
+
+// Language is a middleware handler that negotiates the language of the
+// response from the Accept-Language header. The best match among Supported,
+// by q-value, is stored in the request context, see LanguageFromContext, and
+// sent in the Content-Language header, with Vary: Accept-Language so caches
+// keep a response per language. A language range matches a supported
+// tag equal to it, or one being a subtag or a parent of it ("en" and "en-US").
+// Without a match the first supported language is used.
+type Language struct {
+	Supported []string
+}
+
+// NewLanguage returns a new instance of Language
+func NewLanguage(supported ...string) *Language {
+	return &Language{Supported: supported}
+}
+
+func (l *Language) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if len(l.Supported) == 0 {
+		next(rw, r)
+		return
+	}
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	lang := l.match(r.Header.Get("Accept-Language"))
+	res.Before(func(ResponseWriter) {
+		res.Header().Add("Vary", "Accept-Language")
+		if res.Header().Get("Content-Language") == "" {
+			res.Header().Set("Content-Language", lang)
+		}
+	})
+	next(res, r.WithContext(context.WithValue(r.Context(), languageKey, lang)))
+}
+
+// LanguageFromContext returns the language negotiated by Language for the
+// request the context belongs to, or "" if there is none.
+func LanguageFromContext(ctx context.Context) string {
+	lang, _ := ctx.Value(languageKey).(string)
+	return lang
+}
+
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+func (l *Language) match(header string) string {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+		if q := qValue(fields[1:]); q > 0 {
+			ranges = append(ranges, languageRange{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, lr := range ranges {
+		if lr.tag == "*" {
+			return l.Supported[0]
+		}
+		for _, s := range l.Supported {
+			if strings.EqualFold(s, lr.tag) {
+				return s
+			}
+		}
+		for _, s := range l.Supported {
+			if hasLanguagePrefix(s, lr.tag) || hasLanguagePrefix(lr.tag, s) {
+				return s
+			}
+		}
+	}
+	return l.Supported[0]
+}
+
+// qValue returns the q-value among the params of an Accept-* header element,
+// 1 if there is none or it is malformed.
+func qValue(params []string) float64 {
+	q := 1.0
+	for _, param := range params {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") {
+			if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+				q = v
+			}
+		}
+	}
+	return q
+}
+
+// hasLanguagePrefix reports whether tag is a subtag of prefix, like "en-US"
+// of "en".
+func hasLanguagePrefix(tag, prefix string) bool {
+	return len(tag) > len(prefix) && tag[len(prefix)] == '-' && strings.EqualFold(tag[:len(prefix)], prefix)
+}
@@ -0,0 +1,40 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLanguage(t *testing.T) {
+	var lang string
+	n := New(NewLanguage("en", "fr", "de-CH"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lang = LanguageFromContext(r.Context())
+		rw.Write([]byte("hello"))
+	})
+
+	for header, expected := range map[string]string{
+		"":                             "en",
+		"fr":                           "fr",
+		"de;q=0.5, fr;q=0.9, en;q=0.8": "fr",
+		"es, fr;q=0.1":                 "fr",
+		"FR-ca":                        "fr",
+		"de":                           "de-CH",
+		"es, it":                       "en",
+		"*;q=0.5, fr;q=0.4":            "en",
+		"fr;q=0, en;q=0.1":             "en",
+		"de-CH;q=0.9, de;q=1":          "de-CH",
+	} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		req.Header.Set("Accept-Language", header)
+		n.ServeHTTP(recorder, req)
+
+		if lang != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, header, lang)
+		}
+		expect(t, recorder.Header().Get("Content-Language"), expected)
+		expect(t, recorder.Header().Get("Vary"), "Accept-Language")
+	}
+}
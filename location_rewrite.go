@@ -0,0 +1,32 @@
+package negroni
+
+import (
+	"net/http"
+	"strings"
+)
+
+// LocationRewrite is a middleware handler that replaces occurrences of From
+// with To in the Location header of responses just before it is written, so
+// redirects of a backend behind a proxy do not leak internal hostnames.
+type LocationRewrite struct {
+	From string
+	To   string
+}
+
+// NewLocationRewrite returns a new instance of LocationRewrite
+func NewLocationRewrite(from, to string) *LocationRewrite {
+	return &LocationRewrite{From: from, To: to}
+}
+
+func (l *LocationRewrite) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	res.Before(func(ResponseWriter) {
+		if location := res.Header().Get("Location"); location != "" && l.From != "" {
+			res.Header().Set("Location", strings.Replace(location, l.From, l.To, -1))
+		}
+	})
+	next(res, r)
+}
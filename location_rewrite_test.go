@@ -0,0 +1,29 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocationRewrite(t *testing.T) {
+	n := New(NewLocationRewrite("http://backend.internal:8080", "https://example.com"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			rw.Write([]byte("ok"))
+			return
+		}
+		http.Redirect(rw, r, "http://backend.internal:8080/login?next=/", http.StatusFound)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusFound)
+	expect(t, recorder.Header().Get("Location"), "https://example.com/login?next=/")
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/plain", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Location"), "")
+}
@@ -2,27 +2,164 @@ package negroni
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"text/template"
 	"time"
 )
 
 // LoggerEntry is the structure passed to the template.
 type LoggerEntry struct {
-	StartTime string
-	Status    int
-	Duration  time.Duration
-	Hostname  string
-	Method    string
-	Path      string
-	Request   *http.Request
+	StartTime  string
+	Start      time.Time
+	Status     int
+	Size       int
+	Duration   time.Duration
+	Hostname   string
+	RemoteAddr string
+	Method     string
+	Path       string
+	UserAgent  string
+	Referer    string
+	// TLSVersion and TLSCipher are the negotiated TLS version and cipher
+	// suite, such as "TLS 1.3" and "TLS_AES_128_GCM_SHA256", empty for
+	// plaintext requests.
+	TLSVersion string
+	TLSCipher  string
+	// TraceID and SpanID identify the trace span of the request, when
+	// Logger.TraceContext finds one.
+	TraceID string
+	SpanID  string
+	// TimedOut tells whether the request ran past its deadline, see
+	// TimedOut, or one applied further down the chain by DeadlineBudget or
+	// HeaderDeadline. The timeout is also logged on a line of its own, with
+	// the http.ServeMux pattern the request matched, on Go 1.23 and when no
+	// middleware between the deadline middleware and the ServeMux replaced
+	// the request, as the pattern is recorded on the request it gets.
+	TimedOut bool
+	// Phases are the phases measured by a PhaseTimer placed before Logger,
+	// with Total up to the time of logging, zero without PhaseTimer.
+	Phases  RequestPhases
+	Request *http.Request
+}
+
+// RemoteHost returns the client IP address of RemoteAddr, or "-" if unknown.
+func (e LoggerEntry) RemoteHost() string {
+	if e.RemoteAddr == "" {
+		return "-"
+	}
+	if host, _, err := net.SplitHostPort(e.RemoteAddr); err == nil {
+		return host
+	}
+	return e.RemoteAddr
+}
+
+// DurationMS returns Duration in milliseconds.
+func (e LoggerEntry) DurationMS() float64 {
+	return float64(e.Duration) / float64(time.Millisecond)
+}
+
+// AuthUser returns the user name of the request's basic auth credentials,
+// or "-" if there are none.
+func (e LoggerEntry) AuthUser() string {
+	if e.Request != nil {
+		if user, _, ok := e.Request.BasicAuth(); ok && user != "" {
+			return user
+		}
+	}
+	return "-"
 }
 
 // LoggerDefaultFormat is the format logged used by the default Logger instance.
 var LoggerDefaultFormat = "{{.StartTime}} | {{.Status}} | \t {{.Duration}} | {{.Hostname}} | {{.Method}} {{.Path}}"
 
+// LoggerCombinedFormat is the Apache/NGINX "combined" log format, to be used
+// with SetFormat, or Format(CombinedLogFormat). Values sent by the client are
+// escaped like Apache does, so quotes cannot break the line. Log parsers
+// expect lines to start with the host, so set an ALogger without the
+// "[negroni] " prefix.
+var LoggerCombinedFormat = `{{.RemoteHost}} - {{escape .AuthUser}} [{{.Start.Format "02/Jan/2006:15:04:05 -0700"}}] "{{escape .Method}} {{escape .Request.URL.RequestURI}} {{escape .Request.Proto}}" {{.Status}} {{.Size}} "{{escape .Referer}}" "{{escape .UserAgent}}"`
+
+// LoggerJSONLinesFormat logs entries as JSON objects, one per line, with
+// nested http and client objects, for ingestion by tools such as
+// Elasticsearch. Strings are quoted with the json template function and
+// numbers are logged as JSON numbers. Set an ALogger without the "[negroni] "
+// prefix for the lines to be valid JSON.
+var LoggerJSONLinesFormat = `{"ts":{{json .StartTime}},"http":{"method":{{json .Method}},"path":{{json .Path}},"status":{{.Status}},"size":{{.Size}},"duration_ms":{{.DurationMS}}},"client":{"ip":{{json .RemoteHost}},"user_agent":{{json .UserAgent}}}}`
+
+// LogFormat is a preset format of Logger, see Logger.Format.
+type LogFormat int
+
+const (
+	// DefaultLogFormat is LoggerDefaultFormat.
+	DefaultLogFormat LogFormat = iota
+	// CombinedLogFormat is LoggerCombinedFormat.
+	CombinedLogFormat
+	// JSONLinesLogFormat is LoggerJSONLinesFormat.
+	JSONLinesLogFormat
+)
+
+// loggerFuncs are the functions available to Logger formats. json renders its
+// argument as JSON, and escape escapes it for a quoted field of an Apache log.
+var loggerFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"escape": escapeLogItem,
+}
+
+// escapeLogItem escapes quotes, backslashes and non-printable bytes of s with
+// backslash sequences, like Apache escapes the items of its access logs.
+func escapeLogItem(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\v':
+			b.WriteString(`\v`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, `\x%02x`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// loggerFields maps the field names accepted by Logger.Fields to the template
+// rendering them.
+var loggerFields = map[string]string{
+	"ts":     "{{.StartTime}}",
+	"status": "{{.Status}}",
+	"dur":    "{{.Duration}}",
+	"host":   "{{.Hostname}}",
+	"method": "{{.Method}}",
+	"path":   "{{.Path}}",
+}
+
 // LoggerDefaultDateFormat is the format used for date by the default Logger instance.
 var LoggerDefaultDateFormat = time.RFC3339
 
@@ -36,19 +173,79 @@ type ALogger interface {
 type Logger struct {
 	// ALogger implements just enough log.Logger interface to be compatible with other implementations
 	ALogger
+	// SampleRate is the fraction of requests, between 0 and 1, that get logged.
+	// Responses with a 5xx status are always logged regardless of sampling.
+	// Zero, the zero value, logs every request like 1, and a negative rate
+	// logs only the 5xx responses.
+	SampleRate float64
+	// Hook, if set, is called with every entry instead of rendering the
+	// template to ALogger.
+	Hook func(entry LoggerEntry)
+	// TraceContext, if set, returns the IDs of the trace span in the context
+	// of a request, or empty strings if there is none, to correlate logs with
+	// traces. The tracing subpackage provides one for OpenTelemetry.
+	TraceContext func(ctx context.Context) (traceID, spanID string)
+
 	dateFormat string
 	template   *template.Template
+	clock      clock
+
+	randMu sync.Mutex
+	rand   *rand.Rand
 }
 
 // NewLogger returns a new Logger instance
 func NewLogger() *Logger {
-	logger := &Logger{ALogger: log.New(os.Stdout, "[negroni] ", 0), dateFormat: LoggerDefaultDateFormat}
+	logger := &Logger{
+		ALogger:    log.New(os.Stdout, "[negroni] ", 0),
+		SampleRate: 1,
+		dateFormat: LoggerDefaultDateFormat,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
 	logger.SetFormat(LoggerDefaultFormat)
 	return logger
 }
 
+// SetNow sets the function used to tell the time of requests and measure
+// their duration, time.Now by default.
+func (l *Logger) SetNow(now func() time.Time) {
+	l.clock = now
+}
+
+// Format sets one of the preset formats, as SetFormat would.
+func (l *Logger) Format(format LogFormat) {
+	switch format {
+	case CombinedLogFormat:
+		l.SetFormat(LoggerCombinedFormat)
+	case JSONLinesLogFormat:
+		l.SetFormat(LoggerJSONLinesFormat)
+	default:
+		l.SetFormat(LoggerDefaultFormat)
+	}
+}
+
 func (l *Logger) SetFormat(format string) {
-	l.template = template.Must(template.New("negroni_parser").Parse(format))
+	l.template = template.Must(template.New("negroni_parser").Funcs(loggerFuncs).Parse(format))
+}
+
+// Fields sets the format to log the given fields as space separated key=value
+// pairs, in the given order. Known fields are ts, status, dur, host, method
+// and path. An error is returned, leaving the format unchanged, if a field is
+// unknown.
+func (l *Logger) Fields(fields []string) error {
+	if len(fields) == 0 {
+		return errors.New("negroni: no logger fields given")
+	}
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		tmpl, ok := loggerFields[field]
+		if !ok {
+			return fmt.Errorf("negroni: unknown logger field %q", field)
+		}
+		parts[i] = field + "=" + tmpl
+	}
+	l.SetFormat(strings.Join(parts, " "))
+	return nil
 }
 
 func (l *Logger) SetDateFormat(format string) {
@@ -56,22 +253,97 @@ func (l *Logger) SetDateFormat(format string) {
 }
 
 func (l *Logger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-	start := time.Now()
+	start := l.clock.now()
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	next(res, r)
 
-	next(rw, r)
+	phases := PhasesFromContext(r.Context())
+	if phases != nil {
+		phases.end()
+	}
+
+	timedOut, handler := TimedOut(r.Context()), ""
+	if m, ok := res.(timeoutMarker); ok && !timedOut {
+		timedOut, handler = m.timedOut()
+	}
+	if !timedOut && !l.sampled(res.Status()) {
+		return
+	}
 
-	res := rw.(ResponseWriter)
 	log := LoggerEntry{
-		StartTime: start.Format(l.dateFormat),
-		Status:    res.Status(),
-		Duration:  time.Since(start),
-		Hostname:  r.Host,
-		Method:    r.Method,
-		Path:      r.URL.Path,
-		Request:   r,
+		StartTime:  start.Format(l.dateFormat),
+		Start:      start,
+		Status:     res.Status(),
+		Size:       res.Size(),
+		Duration:   l.clock.since(start),
+		Hostname:   r.Host,
+		RemoteAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		UserAgent:  r.UserAgent(),
+		Referer:    r.Referer(),
+		TimedOut:   timedOut,
+		Request:    r,
+	}
+	if phases != nil {
+		log.Phases = *phases
+	}
+	if l.TraceContext != nil {
+		log.TraceID, log.SpanID = l.TraceContext(r.Context())
+	}
+	if r.TLS != nil {
+		log.TLSVersion = tlsVersionName(r.TLS.Version)
+		log.TLSCipher = tlsCipherName(r.TLS.CipherSuite)
+	}
+
+	if l.Hook != nil {
+		l.Hook(log)
+		return
 	}
 
 	buff := &bytes.Buffer{}
 	l.template.Execute(buff, log)
 	l.Println(buff.String())
+	if timedOut && handler != "" {
+		l.Printf("%s %s timed out after %v in %s", log.Method, log.Path, log.Duration, handler)
+	} else if timedOut {
+		l.Printf("%s %s timed out after %v", log.Method, log.Path, log.Duration)
+	}
+}
+
+// tlsVersionName returns the name of a tls.Version* constant.
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
+
+// sampled reports whether a response with the given status should be logged.
+func (l *Logger) sampled(status int) bool {
+	if l.SampleRate >= 1 || l.SampleRate == 0 || status >= http.StatusInternalServerError {
+		return true
+	}
+	if l.SampleRate < 0 {
+		return false
+	}
+
+	// rand.Rand is not safe for concurrent use
+	l.randMu.Lock()
+	defer l.randMu.Unlock()
+	if l.rand == nil {
+		l.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return l.rand.Float64() < l.SampleRate
 }
@@ -0,0 +1,35 @@
+//go:build go1.23
+// +build go1.23
+
+// the go directive of go.mod would otherwise select the Go 1.21 ServeMux
+//go:debug httpmuxgo121=0
+
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_LoggerTimedOutHandler(t *testing.T) {
+	var buff bytes.Buffer
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "[negroni] ", 0)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	n := New(l, NewHeaderDeadline(10*time.Millisecond))
+	n.UseHandler(mux)
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/users/1", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.Contains(buff.String(), "[negroni] GET /users/1 timed out after "), true)
+	expect(t, strings.HasSuffix(buff.String(), " in GET /users/{id}\n"), true)
+}
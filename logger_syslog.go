@@ -0,0 +1,39 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package negroni
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// syslogWriter is the part of *syslog.Writer used by the Logger.
+type syslogWriter interface {
+	Info(m string) error
+}
+
+// syslogALogger is an ALogger sending every line to syslog at the info level.
+type syslogALogger struct {
+	w syslogWriter
+}
+
+func (s *syslogALogger) Println(v ...interface{}) {
+	s.w.Info(strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+func (s *syslogALogger) Printf(format string, v ...interface{}) {
+	s.w.Info(fmt.Sprintf(format, v...))
+}
+
+// SetSyslog makes the Logger write its lines to w, which sets the facility
+// and tag, e.g. from syslog.New(syslog.LOG_LOCAL0, "myapp"). Lines are still
+// formatted by the template and are sent at the info severity.
+func (l *Logger) SetSyslog(w *syslog.Writer) {
+	l.setSyslog(w)
+}
+
+func (l *Logger) setSyslog(w syslogWriter) {
+	l.ALogger = &syslogALogger{w: w}
+}
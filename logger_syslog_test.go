@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeSyslog struct {
+	lines []string
+}
+
+func (f *fakeSyslog) Info(m string) error {
+	f.lines = append(f.lines, m)
+	return nil
+}
+
+func Test_LoggerSyslog(t *testing.T) {
+	w := &fakeSyslog{}
+
+	l := NewLogger()
+	l.SetFormat("{{.Method}} {{.Path}} {{.Status}}")
+	l.setSyslog(w)
+
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, len(w.lines), 1)
+	expect(t, w.lines[0], "GET /foobar 404")
+}
@@ -2,11 +2,17 @@ package negroni
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"log"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_Logger(t *testing.T) {
@@ -84,3 +90,383 @@ func Test_LoggerCustomFormat(t *testing.T) {
 	n.ServeHTTP(recorder, req)
 	expect(t, strings.TrimSpace(buff.String()), "[negroni] bar "+userAgent+" - 200")
 }
+
+func Test_LoggerSampleRate(t *testing.T) {
+	var buff bytes.Buffer
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.SetFormat("{{.Status}}")
+	l.SampleRate = 0.1
+	l.rand = rand.New(rand.NewSource(1))
+
+	status := http.StatusOK
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(status)
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	const requests = 10000
+	for i := 0; i < requests; i++ {
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	logged := strings.Count(buff.String(), "\n")
+	if logged < requests*8/100 || logged > requests*12/100 {
+		t.Errorf("Expected roughly 10%% of %d requests to be logged, got %d", requests, logged)
+	}
+
+	buff.Reset()
+	status = http.StatusInternalServerError
+	for i := 0; i < 100; i++ {
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	expect(t, strings.Count(buff.String(), "\n"), 100)
+
+	// the zero value logs everything, a negative rate only 5xx
+	for _, c := range []struct {
+		rate   float64
+		logged int
+	}{{0, 10}, {-1, 0}} {
+		buff.Reset()
+		l.SampleRate = c.rate
+		status = http.StatusOK
+		for i := 0; i < 10; i++ {
+			n.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		expect(t, strings.Count(buff.String(), "\n"), c.logged)
+	}
+}
+
+func Test_LoggerHook(t *testing.T) {
+	var buff bytes.Buffer
+	var entry LoggerEntry
+	recorder := httptest.NewRecorder()
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "[negroni] ", 0)
+	l.Hook = func(e LoggerEntry) {
+		entry = e
+	}
+
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+	}))
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	n.ServeHTTP(recorder, req)
+	expect(t, buff.Len(), 0)
+	expect(t, entry.Status, http.StatusCreated)
+	expect(t, entry.Method, "POST")
+	expect(t, entry.Path, "/foobar")
+	expect(t, entry.Hostname, "localhost:3000")
+	refute(t, entry.StartTime, "")
+	refute(t, entry.Request, (*http.Request)(nil))
+}
+
+func Test_LoggerFields(t *testing.T) {
+	var buff bytes.Buffer
+	recorder := httptest.NewRecorder()
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.SetDateFormat("2006")
+	if err := l.Fields([]string{"status", "method", "path", "ts"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	n.ServeHTTP(recorder, req)
+	expect(t, strings.TrimSpace(buff.String()), "status=404 method=GET path=/foobar ts="+time.Now().Format("2006"))
+}
+
+func Test_LoggerFieldsUnknown(t *testing.T) {
+	l := NewLogger()
+	refute(t, l.Fields([]string{"status", "bogus"}), nil)
+	refute(t, l.Fields(nil), nil)
+}
+
+func Test_LoggerUserAgentReferer(t *testing.T) {
+	var buff bytes.Buffer
+	recorder := httptest.NewRecorder()
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.SetFormat(`"{{.Referer}}" "{{.UserAgent}}"`)
+
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("User-Agent", "Negroni-Test/1.0")
+	req.Header.Set("Referer", "http://example.com/start")
+
+	n.ServeHTTP(recorder, req)
+	expect(t, strings.TrimSpace(buff.String()), `"http://example.com/start" "Negroni-Test/1.0"`)
+}
+
+func Test_LoggerCombinedFormat(t *testing.T) {
+	var buff bytes.Buffer
+	recorder := httptest.NewRecorder()
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.SetFormat(LoggerCombinedFormat)
+
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte("not here"))
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar?q=1", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "192.0.2.1:54321"
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("User-Agent", "Negroni-Test/1.0")
+	req.Header.Set("Referer", "http://example.com/start")
+
+	n.ServeHTTP(recorder, req)
+
+	combined := regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([\w:/]+\s[+\-]\d{4})\] "(\S+) (\S+) (\S+)" (\d{3}) (\d+|-) "([^"]*)" "([^"]*)"$`)
+	line := strings.TrimSpace(buff.String())
+	match := combined.FindStringSubmatch(line)
+	if match == nil {
+		t.Fatalf("Expected a combined log line, got %q", line)
+	}
+	expect(t, match[1], "192.0.2.1")
+	expect(t, match[2], "-")
+	expect(t, match[3], "frank")
+	expect(t, match[5], "GET")
+	expect(t, match[6], "/foobar?q=1")
+	expect(t, match[7], "HTTP/1.1")
+	expect(t, match[8], "404")
+	expect(t, match[9], "8")
+	expect(t, match[10], "http://example.com/start")
+	expect(t, match[11], "Negroni-Test/1.0")
+}
+
+func Test_LoggerCombinedFormatEscaping(t *testing.T) {
+	var buff bytes.Buffer
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.Format(CombinedLogFormat)
+	n := New(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.RemoteAddr = "192.0.2.1:54321"
+	req.Header.Set("User-Agent", `Evil "Agent" \ 1.0`+"\t")
+	req.Header.Set("Referer", `http://example.com/"`)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	quoted := `"((?:[^"\\]|\\.)*)"`
+	combined := regexp.MustCompile(`^(\S+) (\S+) (\S+) \[[^\]]+\] ` + quoted + ` (\d{3}) (\d+) ` + quoted + ` ` + quoted + `$`)
+	line := strings.TrimSpace(buff.String())
+	match := combined.FindStringSubmatch(line)
+	if match == nil {
+		t.Fatalf("Expected a combined log line, got %q", line)
+	}
+	expect(t, match[4], "GET / HTTP/1.1")
+	expect(t, match[7], `http://example.com/\"`)
+	expect(t, match[8], `Evil \"Agent\" \\ 1.0\t`)
+
+	expect(t, escapeLogItem("caf\xc3\xa9\x00"), `caf\xc3\xa9\x00`)
+}
+
+func Test_LoggerJSONLinesFormat(t *testing.T) {
+	var buff bytes.Buffer
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.SetFormat(LoggerJSONLinesFormat)
+	l.SetNow(func() time.Time {
+		now = now.Add(1500 * time.Microsecond)
+		return now
+	})
+
+	n := New(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "gone", http.StatusGone)
+	})
+	req, _ := http.NewRequest("GET", "http://localhost:3000/a\"quoted\"path", nil)
+	req.RemoteAddr = "10.0.0.1:4321"
+	req.Header.Set("User-Agent", "test/1.0")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line struct {
+		TS   string `json:"ts"`
+		HTTP struct {
+			Method     string  `json:"method"`
+			Path       string  `json:"path"`
+			Status     int     `json:"status"`
+			Size       int     `json:"size"`
+			DurationMS float64 `json:"duration_ms"`
+		} `json:"http"`
+		Client struct {
+			IP        string `json:"ip"`
+			UserAgent string `json:"user_agent"`
+		} `json:"client"`
+	}
+	expect(t, strings.Count(buff.String(), "\n"), 1)
+	expect(t, json.Unmarshal(buff.Bytes(), &line), nil)
+	expect(t, line.TS, "2020-01-02T03:04:05Z")
+	expect(t, line.HTTP.Method, "GET")
+	expect(t, line.HTTP.Path, `/a"quoted"path`)
+	expect(t, line.HTTP.Status, http.StatusGone)
+	expect(t, line.HTTP.Size, 5)
+	expect(t, line.HTTP.DurationMS, 1.5)
+	expect(t, line.Client.IP, "10.0.0.1")
+	expect(t, line.Client.UserAgent, "test/1.0")
+}
+
+func Test_LoggerTimedOut(t *testing.T) {
+	var buff bytes.Buffer
+	var entries []LoggerEntry
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "[negroni] ", 0)
+	l.SampleRate = -1
+	l.Hook = func(e LoggerEntry) {
+		entries = append(entries, e)
+	}
+
+	n := New(l, NewHeaderDeadline(10*time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+		}
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/fast", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, len(entries), 0)
+
+	req, _ = http.NewRequest("GET", "http://localhost:3000/slow", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, len(entries), 1)
+	expect(t, entries[0].TimedOut, true)
+
+	l.Hook = nil
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.Contains(buff.String(), "[negroni] GET /slow timed out after "), true)
+}
+
+func Test_LoggerCancelledNotTimedOut(t *testing.T) {
+	var entry LoggerEntry
+	l := NewLogger()
+	l.Hook = func(e LoggerEntry) {
+		entry = e
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := New(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		cancel()
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	expect(t, entry.TimedOut, false)
+	expect(t, TimedOut(req.Context()), false)
+}
+
+func Test_LoggerSetNow(t *testing.T) {
+	var buff bytes.Buffer
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "[negroni] ", 0)
+	l.SetFormat("{{.StartTime}} {{.Duration}}")
+	l.SetNow(func() time.Time {
+		now = now.Add(250 * time.Millisecond)
+		return now
+	})
+
+	n := New(l)
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, buff.String(), "[negroni] 2020-01-02T03:04:05Z 250ms\n")
+}
+
+func Test_LoggerTLS(t *testing.T) {
+	var entry LoggerEntry
+	l := NewLogger()
+	l.Hook = func(e LoggerEntry) {
+		entry = e
+	}
+	n := New(l)
+
+	req, _ := http.NewRequest("GET", "https://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, entry.TLSVersion, "")
+	expect(t, entry.TLSCipher, "")
+
+	req.TLS = &tls.ConnectionState{
+		Version:     tls.VersionTLS12,
+		CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, entry.TLSVersion, "TLS 1.2")
+	expect(t, entry.TLSCipher, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+
+	expect(t, tlsVersionName(tls.VersionTLS13), "TLS 1.3")
+	expect(t, tlsVersionName(0x0200), "0x0200")
+}
+
+func Test_LoggerTraceContext(t *testing.T) {
+	var entry LoggerEntry
+	l := NewLogger()
+	l.Hook = func(e LoggerEntry) {
+		entry = e
+	}
+	n := New(l)
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, entry.TraceID, "")
+	expect(t, entry.SpanID, "")
+
+	type spanKey struct{}
+	l.TraceContext = func(ctx context.Context) (string, string) {
+		if span, ok := ctx.Value(spanKey{}).(string); ok {
+			return "trace-" + span, span
+		}
+		return "", ""
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), spanKey{}, "1")))
+	expect(t, entry.TraceID, "trace-1")
+	expect(t, entry.SpanID, "1")
+}
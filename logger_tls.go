@@ -0,0 +1,10 @@
+//go:build go1.14
+// +build go1.14
+
+package negroni
+
+import "crypto/tls"
+
+func tlsCipherName(id uint16) string {
+	return tls.CipherSuiteName(id)
+}
@@ -0,0 +1,11 @@
+//go:build !go1.14
+// +build !go1.14
+
+package negroni
+
+import "fmt"
+
+// tlsCipherName falls back to the hex ID as tls.CipherSuiteName needs Go 1.14.
+func tlsCipherName(id uint16) string {
+	return fmt.Sprintf("0x%04X", id)
+}
@@ -0,0 +1,59 @@
+//go:build go1.19
+// +build go1.19
+
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Maintenance is a middleware handler that answers all requests with a 503,
+// a Retry-After header and Body while Enabled is set, without calling the next
+// handler. The flag is read on every request, so maintenance can be switched
+// on and off live. Requests for one of the AllowPaths, such as health checks,
+// are always served.
+type Maintenance struct {
+	Enabled     *atomic.Bool
+	RetryAfter  time.Duration
+	Body        string
+	ContentType string
+	AllowPaths  []string
+}
+
+// NewMaintenance returns a new instance of Maintenance
+func NewMaintenance(enabled *atomic.Bool, retryAfter time.Duration) *Maintenance {
+	return &Maintenance{
+		Enabled:     enabled,
+		RetryAfter:  retryAfter,
+		Body:        http.StatusText(http.StatusServiceUnavailable),
+		ContentType: "text/plain; charset=utf-8",
+	}
+}
+
+func (m *Maintenance) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !m.Enabled.Load() || m.allowed(r.URL.Path) {
+		next(rw, r)
+		return
+	}
+
+	h := rw.Header()
+	if m.RetryAfter > 0 {
+		h.Set("Retry-After", strconv.FormatInt(int64((m.RetryAfter+time.Second-1)/time.Second), 10))
+	}
+	h.Set("Content-Type", m.ContentType)
+	h.Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusServiceUnavailable)
+	rw.Write([]byte(m.Body))
+}
+
+func (m *Maintenance) allowed(path string) bool {
+	for _, p := range m.AllowPaths {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
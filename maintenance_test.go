@@ -0,0 +1,46 @@
+//go:build go1.19
+// +build go1.19
+
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	var enabled atomic.Bool
+	m := NewMaintenance(&enabled, 90*time.Second)
+	m.AllowPaths = []string{DefaultHealthCheckPath}
+
+	n := New(m)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("served"))
+	})
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve("/")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "served")
+
+	enabled.Store(true)
+	recorder = serve("/")
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+	expect(t, recorder.Header().Get("Retry-After"), "90")
+	expect(t, recorder.Body.String(), "Service Unavailable")
+
+	recorder = serve(DefaultHealthCheckPath)
+	expect(t, recorder.Code, http.StatusOK)
+
+	enabled.Store(false)
+	recorder = serve("/")
+	expect(t, recorder.Code, http.StatusOK)
+}
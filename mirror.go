@@ -0,0 +1,88 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Mirror is a middleware handler that shadows a Rate fraction of the
+// requests, between 0 and 1, to Target, such as a new version of the service
+// being rolled out. The copy is sent in the background with Client once the
+// request body is buffered, and its response is discarded, so the client is
+// served by the next handler as usual. Requests with a body larger than
+// MaxBodySize are not mirrored.
+type Mirror struct {
+	Target      *url.URL
+	Rate        float64
+	Client      *http.Client
+	MaxBodySize int64
+}
+
+// NewMirror returns a new instance of Mirror giving up on shadow requests
+// after 5 seconds and mirroring bodies up to 1MB.
+func NewMirror(target *url.URL, rate float64) *Mirror {
+	return &Mirror{
+		Target:      target,
+		Rate:        rate,
+		Client:      &http.Client{Timeout: 5 * time.Second},
+		MaxBodySize: 1 << 20,
+	}
+}
+
+func (m *Mirror) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if m.Rate <= 0 || (m.Rate < 1 && rand.Float64() >= m.Rate) {
+		next(rw, r)
+		return
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		// a read error is left for the handler to hit on the remainder
+		body, _ = ioutil.ReadAll(io.LimitReader(r.Body, m.MaxBodySize+1))
+		r.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+		if int64(len(body)) > m.MaxBodySize {
+			next(rw, r)
+			return
+		}
+	}
+
+	if shadow, err := m.shadowRequest(r, body); err == nil {
+		go m.send(shadow)
+	}
+	next(rw, r)
+}
+
+// shadowRequest copies r for Target.
+func (m *Mirror) shadowRequest(r *http.Request, body []byte) (*http.Request, error) {
+	u := *m.Target
+	u.Path = strings.TrimSuffix(u.Path, "/") + r.URL.Path
+	u.RawPath = ""
+	u.RawQuery = r.URL.RawQuery
+
+	// not tied to r, which is canceled once the client is served
+	shadow, err := http.NewRequest(r.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	shadow = shadow.WithContext(context.Background())
+	for k, v := range r.Header {
+		shadow.Header[k] = append([]string(nil), v...)
+	}
+	return shadow, nil
+}
+
+func (m *Mirror) send(shadow *http.Request) {
+	res, err := m.Client.Do(shadow)
+	if err != nil {
+		return
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+}
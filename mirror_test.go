@@ -0,0 +1,65 @@
+package negroni
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mirrored struct {
+	method, path, query, header, body string
+}
+
+func TestMirror(t *testing.T) {
+	received := make(chan mirrored, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- mirrored{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Test"), string(body)}
+		http.Error(rw, "shadow failure", http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	target, _ := url.Parse(shadow.URL + "/shadow/")
+	n := New(NewMirror(target, 1))
+	var primaryBody string
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		primaryBody = string(body)
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("primary"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/orders?id=7", strings.NewReader("payload"))
+	req.Header.Set("X-Test", "yes")
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusCreated)
+	expect(t, recorder.Body.String(), "primary")
+	expect(t, primaryBody, "payload")
+
+	select {
+	case m := <-received:
+		expect(t, m, mirrored{"POST", "/shadow/orders", "id=7", "yes", "payload"})
+	case <-time.After(time.Second):
+		t.Fatal("the shadow did not receive the request")
+	}
+}
+
+func TestMirror_notSampled(t *testing.T) {
+	target, _ := url.Parse("http://127.0.0.1:1")
+	n := New(NewMirror(target, 0))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("primary"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Body.String(), "primary")
+}
@@ -1,16 +1,41 @@
 package negroni
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"sync"
 )
 
 const (
 	// DefaultAddress is used if no other is specified.
 	DefaultAddress = ":8080" // 默认路由地址
+
+	// EnvDevelopment and EnvProduction are the environments known by ClassicFor.
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
 )
 
+// NegroniContextKey is a context key. It can be used in handlers with
+// context.WithValue to access the Negroni instance serving the request.
+// The associated value will be of type *Negroni.
+var NegroniContextKey = &contextKey{"negroni"}
+
+// contextKey is a value for use with context.WithValue. It's used as
+// a pointer so it fits in an interface{} without allocation.
+// All the context values of the package use a *contextKey key read through
+// an accessor function, such as FromContext, so they cannot collide with the
+// keys of other packages, even ones with the same name.
+type contextKey struct {
+	name string
+}
+
+func (k *contextKey) String() string { return "negroni context value " + k.name }
+
 // Handler handler is an interface that objects can implement to be registered to serve as middleware
 // in the Negroni middleware stack.
 // ServeHTTP should yield to the next middleware in the chain by invoking the next http.HandlerFunc
@@ -84,14 +109,25 @@ func WrapFunc(handlerFunc http.HandlerFunc) Handler {
 type Negroni struct {
 	middleware middleware // 头middleware
 	handlers   []Handler  // 所有middleware的handler，方便在有新的handler加入时，重建middleware链
+	router     http.Handler
+	priorities []int // priorities of handlers, missing entries are 0
+
+	shortCircuit  func(r *http.Request, index int)
+	transparent   bool
+	maxChainDepth int
+
+	hooksMu       sync.Mutex
+	shutdownHooks []func(context.Context) error
 }
 
-// New returns a new Negroni instance with no middleware preconfigured.
+// New returns a new Negroni instance with no middleware preconfigured. Its
+// chain depth is limited to DefaultMaxChainDepth.
 func New(handlers ...Handler) *Negroni {
-	return &Negroni{
-		handlers:   handlers,
-		middleware: build(handlers),
-	}
+	n := &Negroni{maxChainDepth: DefaultMaxChainDepth}
+	n.checkDepth(len(handlers))
+	n.handlers = handlers
+	n.middleware = build(handlers)
+	return n
 }
 
 // With returns a new Negroni instance that is a combination of the negroni
@@ -100,9 +136,15 @@ func New(handlers ...Handler) *Negroni {
 func (n *Negroni) With(handlers ...Handler) *Negroni {
 	currentHandlers := make([]Handler, len(n.handlers))
 	copy(currentHandlers, n.handlers)
-	return New(
-		append(currentHandlers, handlers...)...,
-	)
+	nn := &Negroni{
+		handlers:      currentHandlers,
+		priorities:    append([]int(nil), n.priorities...),
+		router:        n.router,
+		maxChainDepth: n.maxChainDepth,
+	}
+	nn.insert(0, handlers...)
+	nn.rebuild()
+	return nn
 }
 
 // Classic returns a new Negroni instance with the default middleware already
@@ -116,9 +158,114 @@ func Classic() *Negroni {
 	return New(NewRecovery(), NewLogger(), NewStatic(http.Dir("public")))
 }
 
+// ClassicNoStatic returns a new Negroni instance with the default middleware
+// of Classic except Static, for services without a public directory.
+//
+// Recovery - Panic Recovery Middleware
+// Logger - Request/Response Logging
+func ClassicNoStatic() *Negroni {
+	return New(NewRecovery(), NewLogger())
+}
+
+// ClassicFor returns a new Negroni instance with the default middleware of
+// Classic configured for the environment env. If env is empty the
+// NEGRONI_ENV environment variable is used.
+//
+// In EnvProduction the Logger only logs failed (5xx) requests and Recovery
+// does not write stack traces to the response, it still logs them. Any other
+// environment behaves like Classic.
+func ClassicFor(env string) *Negroni {
+	if env == "" {
+		env = os.Getenv("NEGRONI_ENV")
+	}
+	recovery := NewRecovery()
+	logger := NewLogger()
+	if env == EnvProduction {
+		recovery.PrintStack = false
+		logger.SampleRate = 0
+	}
+	return New(recovery, logger, NewStatic(http.Dir("public")))
+}
+
 // 实现http.Handler
 func (n *Negroni) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	n.middleware.ServeHTTP(NewResponseWriter(rw), r)
+	if len(n.handlers) == 0 && n.router == nil {
+		// nothing would ever write to rw, skip wrapping it (1 allocation) and
+		// walking the void middleware
+		return
+	}
+	if n.transparent {
+		n.serve(rw, r)
+		return
+	}
+	nrw := NewResponseWriter(rw)
+	n.serve(nrw, r)
+	if after, ok := nrw.(interface{ callAfter() }); ok {
+		after.callAfter()
+	}
+}
+
+// SetTransparentWriter sets whether ServeHTTP passes the http.ResponseWriter
+// it is given to the chain unmodified instead of wrapping it in a
+// ResponseWriter, like ServeHTTPRaw does. This suits fronting a proxy such as
+// httputil.ReverseProxy, which then deals with the writer of the server
+// directly for flushing and trailers.
+//
+// The tradeoff is the same as in ServeHTTPRaw: the chain gets no Status or
+// Size tracking and no Before or After callbacks unless a middleware wraps the
+// writer itself, as the bundled ones do when they need to.
+func (n *Negroni) SetTransparentWriter(transparent bool) {
+	n.transparent = transparent
+}
+
+// ServeHTTPRaw serves the middleware chain with rw as given, without wrapping
+// it in a ResponseWriter. This avoids double wrapping when an outer layer
+// already provides a ResponseWriter.
+//
+// Caveat: middleware relying on the ResponseWriter interface (Status, Size,
+// Before, After) only works if rw implements it. The bundled middleware falls
+// back to wrapping rw itself when it does not. Functions registered with After
+// are left for the owner of rw to call.
+func (n *Negroni) ServeHTTPRaw(rw http.ResponseWriter, r *http.Request) {
+	n.serve(rw, r)
+}
+
+func (n *Negroni) serve(rw http.ResponseWriter, r *http.Request) {
+	if r == nil {
+		n.middleware.ServeHTTP(rw, r)
+		return
+	}
+	ctx := context.WithValue(r.Context(), NegroniContextKey, n)
+	if n.shortCircuit == nil {
+		n.middleware.ServeHTTP(rw, r.WithContext(ctx))
+		return
+	}
+
+	depth := -1
+	r = r.WithContext(context.WithValue(ctx, chainDepthKey, &depth))
+	n.middleware.ServeHTTP(rw, r)
+	if depth < len(n.handlers) {
+		n.shortCircuit(r, depth)
+	}
+}
+
+var chainDepthKey = &contextKey{"chain-depth"}
+
+// OnShortCircuit registers fn to be called after serving a request that did
+// not get through the whole chain, with the index in Handlers of the handler
+// that did not call next. Reaching the Router counts as getting through. This
+// is meant to catch middleware forgetting to call next while debugging: it
+// costs a context value per request and a lookup per handler.
+func (n *Negroni) OnShortCircuit(fn func(r *http.Request, index int)) {
+	n.shortCircuit = fn
+	n.rebuild()
+}
+
+// FromContext returns the Negroni instance serving the request the context
+// belongs to, or nil if there is none.
+func FromContext(ctx context.Context) *Negroni {
+	n, _ := ctx.Value(NegroniContextKey).(*Negroni)
+	return n
 }
 
 // Use adds a Handler onto the middleware stack. Handlers are invoked in the order they are added to a Negroni.
@@ -127,8 +274,175 @@ func (n *Negroni) Use(handler Handler) {
 		panic("handler cannot be nil")
 	}
 
-	n.handlers = append(n.handlers, handler)
-	n.middleware = build(n.handlers) // 重新建立middleware
+	n.insert(0, handler)
+	n.rebuild() // 重新建立middleware
+}
+
+var parentKey = &contextKey{"parent"}
+
+// parentChain is what a stack mounted with AsHandler hands control back to.
+type parentChain struct {
+	next    http.HandlerFunc
+	negroni *Negroni
+	// outer is the parentChain of the parent itself, if it is mounted too
+	outer *parentChain
+}
+
+// AsHandler returns the Negroni middleware stack as a single Handler, so it can
+// be mounted in another Negroni. When the request reaches the end of n's
+// chain, the next handler of the parent stack is called, unless n has a Router
+// which then ends the chain. The returned Handler uses the handlers of n at the
+// time AsHandler is called.
+func (n *Negroni) AsHandler() Handler {
+	handlers := make([]Handler, len(n.handlers), len(n.handlers)+1)
+	copy(handlers, n.handlers)
+	if n.router != nil {
+		handlers = append(handlers, terminal(n.router))
+	} else {
+		handlers = append(handlers, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, _ http.HandlerFunc) {
+			parent := r.Context().Value(parentKey).(*parentChain)
+			ctx := context.WithValue(r.Context(), parentKey, parent.outer)
+			ctx = context.WithValue(ctx, NegroniContextKey, parent.negroni)
+			parent.next(rw, r.WithContext(ctx))
+		}))
+	}
+	chain := build(handlers)
+
+	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		outer, _ := r.Context().Value(parentKey).(*parentChain)
+		parent := &parentChain{next: next, negroni: FromContext(r.Context()), outer: outer}
+		ctx := context.WithValue(r.Context(), parentKey, parent)
+		ctx = context.WithValue(ctx, NegroniContextKey, n)
+		chain.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// UseAll adds several Handlers onto the middleware stack, in order, rebuilding
+// the chain only once. Like Use it panics if any handler is nil, in which case
+// none of them are added.
+func (n *Negroni) UseAll(handlers ...Handler) {
+	for _, handler := range handlers {
+		if handler == nil {
+			panic("handler cannot be nil")
+		}
+	}
+
+	n.insert(0, handlers...)
+	n.rebuild()
+}
+
+// UseSafe is like UseAll, but skips nil handlers instead of panicking, which
+// suits stacks built from plugin-provided slices. It returns how many handlers
+// were added.
+func (n *Negroni) UseSafe(handlers ...Handler) int {
+	added := make([]Handler, 0, len(handlers))
+	for _, handler := range handlers {
+		if handler != nil {
+			added = append(added, handler)
+		}
+	}
+	if len(added) > 0 {
+		n.insert(0, added...)
+		n.rebuild()
+	}
+	return len(added)
+}
+
+// UsePriority adds a Handler onto the middleware stack with the given
+// priority. The stack is kept in ascending priority whatever order handlers
+// are added in, and handlers with the same priority run in the order they are
+// added. Handlers added with the other methods have priority 0, so they run
+// after the ones with a negative priority and before the ones with a positive
+// priority, even when added later.
+func (n *Negroni) UsePriority(priority int, handler Handler) {
+	if handler == nil {
+		panic("handler cannot be nil")
+	}
+
+	n.insert(priority, handler)
+	n.rebuild()
+}
+
+// insert adds handlers to the stack with the given priority, after the
+// handlers with a lower or equal one. It does not rebuild the chain.
+func (n *Negroni) insert(priority int, handlers ...Handler) {
+	n.checkDepth(len(handlers))
+	if len(n.priorities) == 0 && priority == 0 {
+		// no priorities were ever given, so the stack is in insertion order
+		n.handlers = append(n.handlers, handlers...)
+		return
+	}
+
+	for len(n.priorities) < len(n.handlers) {
+		n.priorities = append(n.priorities, 0)
+	}
+	i := 0
+	for i < len(n.handlers) && n.priorities[i] <= priority {
+		i++
+	}
+
+	hs := make([]Handler, 0, len(n.handlers)+len(handlers))
+	hs = append(hs, n.handlers[:i]...)
+	hs = append(hs, handlers...)
+	n.handlers = append(hs, n.handlers[i:]...)
+	ps := make([]int, 0, len(n.handlers))
+	ps = append(ps, n.priorities[:i]...)
+	for range handlers {
+		ps = append(ps, priority)
+	}
+	n.priorities = append(ps, n.priorities[i:]...)
+}
+
+// Router sets h as the terminal of the middleware chain: it is invoked after
+// all the handlers, including ones added later, and nothing runs after it.
+// Unlike UseHandler, h is not wrapped into a Handler calling next. Router is
+// not part of Handlers.
+func (n *Negroni) Router(h http.Handler) *Negroni {
+	if n.router == nil && h != nil {
+		n.checkDepth(1)
+	}
+	n.router = h
+	n.rebuild()
+	return n
+}
+
+// rebuild rebuilds the middleware chain from the handlers and router.
+func (n *Negroni) rebuild() {
+	handlers := n.handlers
+	if n.shortCircuit != nil {
+		handlers = make([]Handler, 0, len(n.handlers)+1)
+		for i, h := range n.handlers {
+			handlers = append(handlers, trackDepth(h, i))
+		}
+		handlers = append(handlers, trackDepth(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(rw, r)
+		}), len(n.handlers)))
+	}
+	if n.router != nil {
+		// the full slice expression makes append copy instead of writing
+		// into the spare capacity of n.handlers
+		handlers = append(handlers[:len(handlers):len(handlers)], terminal(n.router))
+	}
+	n.middleware = build(handlers)
+}
+
+// trackDepth wraps h to record index as the depth reached by the request.
+func trackDepth(h Handler, index int) Handler {
+	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if r != nil {
+			if depth, ok := r.Context().Value(chainDepthKey).(*int); ok {
+				*depth = index
+			}
+		}
+		h.ServeHTTP(rw, r, next)
+	})
+}
+
+// terminal converts h into a Handler that never calls next.
+func terminal(h http.Handler) Handler {
+	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, _ http.HandlerFunc) {
+		h.ServeHTTP(rw, r)
+	})
 }
 
 // UseFunc adds a Negroni-style handler function onto the middleware stack.
@@ -148,8 +462,9 @@ func (n *Negroni) UseHandlerFunc(handlerFunc func(rw http.ResponseWriter, r *htt
 
 // Run is a convenience function that runs the negroni stack as an HTTP
 // server. The addr string, if provided, takes the same format as http.ListenAndServe.
-// If no address is provided but the PORT environment variable is set, the PORT value is used.
-// If neither is provided, the address' value will equal the DefaultAddress constant.
+// If no address is provided, it is made of the HOST and PORT environment
+// variables, the missing one taken from the DefaultAddress constant. If
+// neither is set, the address' value will equal the DefaultAddress constant.
 func (n *Negroni) Run(addr ...string) {
 	l := log.New(os.Stdout, "[negroni] ", 0)
 	finalAddr := detectAddress(addr...)
@@ -157,14 +472,40 @@ func (n *Negroni) Run(addr ...string) {
 	l.Fatal(http.ListenAndServe(finalAddr, n))
 }
 
+// RunTLSConfig is like Run, but serves HTTPS using cfg. The certificates must
+// be provided by cfg, through Certificates or GetCertificate. This allows
+// setting things like MinVersion, CipherSuites and ClientAuth for mTLS.
+// An empty addr is resolved like in Run.
+func (n *Negroni) RunTLSConfig(addr string, cfg *tls.Config) {
+	l := log.New(os.Stdout, "[negroni] ", 0)
+	if addr == "" {
+		addr = detectAddress()
+	}
+	server := n.tlsServer(addr, cfg)
+	l.Printf("listening on %s", addr)
+	l.Fatal(server.ListenAndServeTLS("", ""))
+}
+
+func (n *Negroni) tlsServer(addr string, cfg *tls.Config) *http.Server {
+	return &http.Server{Addr: addr, Handler: n, TLSConfig: cfg}
+}
+
 func detectAddress(addr ...string) string {
 	if len(addr) > 0 {
 		return addr[0]
 	}
-	if port := os.Getenv("PORT"); port != "" {
-		return ":" + port
+	host, port := os.Getenv("HOST"), os.Getenv("PORT")
+	if host == "" && port == "" {
+		return DefaultAddress
 	}
-	return DefaultAddress
+	defaultHost, defaultPort, _ := net.SplitHostPort(DefaultAddress)
+	if host == "" {
+		host = defaultHost
+	}
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(host, port)
 }
 
 // Returns a list of all the handlers in the current Negroni middleware chain.
@@ -172,6 +513,39 @@ func (n *Negroni) Handlers() []Handler {
 	return n.handlers
 }
 
+// Walk calls fn for every handler in the middleware chain, in the order they
+// are executed, together with its position in the chain.
+func (n *Negroni) Walk(fn func(index int, h Handler)) {
+	// the chain is built from n.handlers, so they share the same order
+	for i, h := range n.handlers {
+		fn(i, h)
+	}
+}
+
+// DefaultMaxChainDepth is the maximum chain depth of the instances returned
+// by New.
+const DefaultMaxChainDepth = 256
+
+// SetMaxChainDepth sets the maximum number of handlers in the middleware
+// chain, counting the Router. Adding handlers past it panics, leaving the
+// chain as it was, which catches accidental recursive composition early. Zero
+// or less means no limit. It does not check the handlers already added.
+func (n *Negroni) SetMaxChainDepth(depth int) {
+	n.maxChainDepth = depth
+}
+
+// checkDepth panics if adding added handlers to the chain would make it
+// deeper than the maximum chain depth.
+func (n *Negroni) checkDepth(added int) {
+	depth := len(n.handlers) + added
+	if n.router != nil {
+		depth++
+	}
+	if n.maxChainDepth > 0 && depth > n.maxChainDepth {
+		panic(fmt.Sprintf("negroni: chain of %d handlers exceeds the maximum chain depth of %d", depth, n.maxChainDepth))
+	}
+}
+
 func build(handlers []Handler) middleware {
 	var next middleware
 	// 最终形成的链条 middleware1 -> middleware2 -> middleware3 -> voidMiddleware
@@ -31,3 +31,13 @@ func BenchmarkNegroni(b *testing.B) {
 		n.ServeHTTP(nil, nil)
 	}
 }
+
+func BenchmarkNegroniEmpty(b *testing.B) {
+	n := New()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.ServeHTTP(nil, nil)
+	}
+}
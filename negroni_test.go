@@ -1,11 +1,25 @@
 package negroni
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"os"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 /* Test Helpers */
@@ -26,6 +40,46 @@ func TestNegroniRun(t *testing.T) {
 	go New().Run(":3000")
 }
 
+func TestNegroniTLSServer(t *testing.T) {
+	// borrow the certificate of a throwaway test server
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+	certs := ts.TLS.Certificates
+
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	server := n.tlsServer("127.0.0.1:0", &tls.Config{Certificates: certs, MinVersion: tls.VersionTLS13})
+	server.ErrorLog = log.New(ioutil.Discard, "", 0)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.ServeTLS(ln, "", "")
+	defer server.Close()
+
+	get := func(maxVersion uint16) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true, MaxVersion: maxVersion},
+		}}
+		return client.Get("https://" + ln.Addr().String())
+	}
+
+	res, err := get(tls.VersionTLS13)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	expect(t, res.StatusCode, http.StatusNoContent)
+	expect(t, res.TLS.Version, uint16(tls.VersionTLS13))
+
+	if _, err := get(tls.VersionTLS12); err == nil {
+		t.Error("Expected a TLS 1.2 client to be rejected")
+	}
+}
+
 func TestNegroniWith(t *testing.T) {
 	result := ""
 	response := httptest.NewRecorder()
@@ -99,6 +153,41 @@ func TestNegroniWith_doNotModifyOriginal(t *testing.T) {
 	expect(t, result, "onethree")
 }
 
+func TestClassicFor(t *testing.T) {
+	for env, production := range map[string]bool{
+		EnvDevelopment: false,
+		EnvProduction:  true,
+		"staging":      false,
+	} {
+		handlers := ClassicFor(env).Handlers()
+		expect(t, len(handlers), 3)
+		expect(t, handlers[0].(*Recovery).PrintStack, !production)
+		expect(t, handlers[0].(*Recovery).LogStack, true)
+		expect(t, handlers[1].(*Logger).SampleRate == 0, production)
+		_, ok := handlers[2].(*Static)
+		expect(t, ok, true)
+	}
+}
+
+func TestClassicNoStatic(t *testing.T) {
+	handlers := ClassicNoStatic().Handlers()
+	expect(t, len(handlers), 2)
+	_, ok := handlers[0].(*Recovery)
+	expect(t, ok, true)
+	_, ok = handlers[1].(*Logger)
+	expect(t, ok, true)
+}
+
+func TestClassicFor_environment(t *testing.T) {
+	defer os.Unsetenv("NEGRONI_ENV")
+
+	os.Setenv("NEGRONI_ENV", EnvProduction)
+	expect(t, ClassicFor("").Handlers()[0].(*Recovery).PrintStack, false)
+
+	os.Unsetenv("NEGRONI_ENV")
+	expect(t, ClassicFor("").Handlers()[0].(*Recovery).PrintStack, true)
+}
+
 func TestNegroniServeHTTP(t *testing.T) {
 	result := ""
 	response := httptest.NewRecorder()
@@ -125,6 +214,246 @@ func TestNegroniServeHTTP(t *testing.T) {
 	expect(t, response.Code, http.StatusBadRequest)
 }
 
+func TestNegroniServeHTTP_after(t *testing.T) {
+	result := ""
+	status, size := 0, 0
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		res := rw.(ResponseWriter)
+		after, ok := rw.(interface{ After(func()) })
+		expect(t, ok, true)
+		after.After(func() {
+			result += "first"
+			status, size = res.Status(), res.Size()
+		})
+		after.After(func() {
+			result += "second"
+		})
+		next(rw, r)
+		result += "returned"
+	})
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("created"))
+	})
+
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "returnedsecondfirst")
+	expect(t, status, http.StatusCreated)
+	expect(t, size, 7)
+}
+
+func TestNegroniAsHandler(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	child := New()
+	child.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "[child1"
+		expect(t, FromContext(r.Context()), child)
+		next(rw, r)
+		result += "]"
+	})
+	child.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "[child2"
+		next(rw, r)
+		result += "]"
+	})
+
+	parent := New()
+	parent.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "[before"
+		next(rw, r)
+		result += "]"
+	})
+	parent.Use(child.AsHandler())
+	parent.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "[after]"
+		expect(t, FromContext(r.Context()), parent)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	parent.ServeHTTP(response, req)
+	expect(t, result, "[before[child1[child2[after]]]]")
+}
+
+func TestNegroniAsHandler_nested(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+	step := func(name string) HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += name
+			next(rw, r)
+		}
+	}
+
+	inner := New(step("c"))
+	middle := New(step("b"), inner.AsHandler(), step("d"))
+	outer := New(step("a"), middle.AsHandler(), step("e"))
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	outer.ServeHTTP(response, req)
+	expect(t, result, "abcde")
+}
+
+func TestNegroniRouter(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+	step := func(name string) HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += name
+			next(rw, r)
+			result += "]"
+		}
+	}
+
+	n := New(step("[a"))
+	n.Router(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "[router]"
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	n.Use(step("[b"))
+	n.UseAll(step("[c"))
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(response, req)
+	expect(t, result, "[a[b[c[router]]]]")
+	expect(t, response.Code, http.StatusTeapot)
+	expect(t, len(n.Handlers()), 3)
+
+	result = ""
+	n.With(step("[d")).ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, result, "[a[b[c[d[router]]]]]")
+}
+
+func TestNegroniRouter_asHandler(t *testing.T) {
+	result := ""
+	child := New()
+	child.Router(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "router"
+	}))
+
+	parent := New(child.AsHandler())
+	parent.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "after"
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	parent.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, result, "router")
+}
+
+func TestNegroniOnShortCircuit(t *testing.T) {
+	pass := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	stop := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.WriteHeader(http.StatusForbidden)
+	})
+
+	index := -2
+	n := New(pass, stop, pass)
+	n.OnShortCircuit(func(r *http.Request, i int) {
+		index = i
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, index, 1)
+
+	index = -2
+	full := New(pass, pass)
+	full.OnShortCircuit(func(r *http.Request, i int) {
+		index = i
+	})
+	full.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, index, -2)
+
+	full.Router(http.NotFoundHandler())
+	full.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, index, -2)
+
+	full.Use(stop)
+	full.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, index, 2)
+}
+
+func TestNegroniUsePriority(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+	step := func(name string) HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += name
+			next(rw, r)
+		}
+	}
+
+	n := New(step("b"))
+	n.UsePriority(10, step("e"))
+	n.UsePriority(-5, step("a"))
+	n.UsePriority(0, step("c"))
+	n.UsePriority(10, step("f"))
+	n.UsePriority(5, step("d"))
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(response, req)
+	expect(t, result, "abcdef")
+	expect(t, len(n.Handlers()), 6)
+
+	// handlers added without a priority have priority 0
+	result = ""
+	nn := n.With(step("g"))
+	nn.UsePriority(7, step("x"))
+	nn.ServeHTTP(response, req)
+	expect(t, result, "abcgdxef")
+
+	result = ""
+	n.Use(step("y"))
+	n.UseAll(step("z"))
+	n.ServeHTTP(response, req)
+	expect(t, result, "abcyzdef")
+}
+
+func TestNegroni_UsePriority_Nil(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err == nil {
+			t.Errorf("Expected negroni.UsePriority(nil) to panic, but it did not")
+		}
+	}()
+
+	n := New()
+	n.UsePriority(1, nil)
+}
+
+func TestNegroniSetMaxChainDepth(t *testing.T) {
+	n := New(&voidHandler{}, &voidHandler{})
+	n.SetMaxChainDepth(3)
+	n.Router(http.NotFoundHandler())
+	func() {
+		defer func() {
+			err := recover()
+			refute(t, err, nil)
+			expect(t, err, "negroni: chain of 4 handlers exceeds the maximum chain depth of 3")
+		}()
+		n.Use(&voidHandler{})
+	}()
+	// the chain is left as it was
+	expect(t, len(n.Handlers()), 2)
+	response := httptest.NewRecorder()
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	expect(t, response.Code, http.StatusNotFound)
+
+	// other instances keep the default
+	expect(t, len(New(make([]Handler, DefaultMaxChainDepth)...).Handlers()), DefaultMaxChainDepth)
+
+	n.SetMaxChainDepth(0)
+	n.Use(&voidHandler{})
+	expect(t, len(n.Handlers()), 3)
+}
+
 // Ensures that a Negroni middleware chain
 // can correctly return all of its handlers.
 func TestHandlers(t *testing.T) {
@@ -148,6 +477,95 @@ func TestHandlers(t *testing.T) {
 	expect(t, response.Code, http.StatusOK)
 }
 
+func TestNegroniWalk(t *testing.T) {
+	var served []string
+	named := func(name string) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			served = append(served, name)
+		})
+	}
+	n := New(named("one"), named("two"))
+	n.Use(named("three"))
+
+	var walked []int
+	n.Walk(func(index int, h Handler) {
+		walked = append(walked, index)
+		// serve the handler alone to find out which one it is
+		h.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil), nil)
+	})
+
+	expect(t, reflect.DeepEqual(walked, []int{0, 1, 2}), true)
+	expect(t, strings.Join(served, ","), "one,two,three")
+
+	New().Walk(func(int, Handler) {
+		t.Error("Expected no handlers to be walked")
+	})
+}
+
+func TestNegroniUseAll(t *testing.T) {
+	calls := map[string]int{}
+	response := httptest.NewRecorder()
+	count := func(name string) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			calls[name]++
+			next(rw, r)
+		})
+	}
+
+	n := New(count("one"))
+	n.UseAll(count("two"), count("three"))
+
+	i := 0
+	n.Walk(func(index int, h Handler) {
+		expect(t, index, i)
+		i++
+	})
+	expect(t, i, 3)
+
+	// every handler runs once per request
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, len(calls), 3)
+	for name, c := range calls {
+		if c != 1 {
+			t.Errorf("Expected %s to run once, ran %d times", name, c)
+		}
+	}
+}
+
+func TestNegroniUseAll_Nil(t *testing.T) {
+	n := New()
+	defer func() {
+		err := recover()
+		if err == nil {
+			t.Errorf("Expected negroni.UseAll with a nil handler to panic, but it did not")
+		}
+		expect(t, len(n.Handlers()), 0)
+	}()
+
+	n.UseAll(&voidHandler{}, nil)
+}
+
+func TestNegroniUseSafe(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+	step := func(name string) HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += name
+			next(rw, r)
+		}
+	}
+
+	n := New(step("a"))
+	expect(t, n.UseSafe(nil, step("b"), nil, nil, step("c"), nil), 2)
+	expect(t, n.UseSafe(nil), 0)
+	expect(t, n.UseSafe(), 0)
+	expect(t, len(n.Handlers()), 3)
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(response, req)
+	expect(t, result, "abc")
+}
+
 func TestNegroni_Use_Nil(t *testing.T) {
 	defer func() {
 		err := recover()
@@ -160,6 +578,204 @@ func TestNegroni_Use_Nil(t *testing.T) {
 	n.Use(nil)
 }
 
+func TestFromContext(t *testing.T) {
+	var got *Negroni
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(response, req)
+	expect(t, got, n)
+
+	// the instance is only referenced for the lifetime of the request
+	expect(t, FromContext(req.Context()), (*Negroni)(nil))
+}
+
+func TestFromContext_collectable(t *testing.T) {
+	collected := make(chan struct{})
+	func() {
+		n := New()
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+		runtime.SetFinalizer(n, func(*Negroni) { close(collected) })
+
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Error("Expected the Negroni instance to be garbage collected")
+}
+
+func TestFromContext_nested(t *testing.T) {
+	var got *Negroni
+	response := httptest.NewRecorder()
+
+	inner := New()
+	inner.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	})
+	outer := New()
+	outer.UseHandler(inner)
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	outer.ServeHTTP(response, req)
+	expect(t, got, inner)
+}
+
+// contextKeys lists every package-level context key with a value of the type
+// stored under it. Keys of files with build constraints are added by their
+// tests.
+var contextKeys = map[*contextKey]interface{}{
+	NegroniContextKey: New(),
+	basicAuthUserKey:  "user",
+	baggageKey:        map[string]string{"k": "v"},
+	chainDepthKey:     new(int),
+	cspNonceKey:       "nonce",
+	errGroupKey:       &errgroup.Group{},
+	featureFlagsKey:   map[string]bool{"flag": true},
+	languageKey:       "fr",
+	originalPathKey:   "/original",
+	parentKey:         &parentChain{},
+	phasesKey:         &RequestPhases{},
+	recoveryKey:       NewRecovery(),
+	responseLimitKey:  &limitResponseWriter{exceeded: true},
+	serverTimingKey:   &serverTimings{},
+	tenantKey:         "acme",
+}
+
+func TestContextKeys_noCollision(t *testing.T) {
+	names := map[string]bool{}
+	ctx := context.Background()
+	for key, value := range contextKeys {
+		if names[key.name] {
+			t.Errorf("Context key name %q is used twice", key.name)
+		}
+		names[key.name] = true
+		ctx = context.WithValue(ctx, key, value)
+	}
+	// same-named keys of other packages
+	for name := range names {
+		ctx = context.WithValue(ctx, name, "string value")
+		ctx = context.WithValue(ctx, contextKey{name}, "string value")
+	}
+
+	for key, value := range contextKeys {
+		if !reflect.DeepEqual(ctx.Value(key), value) {
+			t.Errorf("Context key %v does not round-trip", key)
+		}
+	}
+	expect(t, FromContext(ctx), contextKeys[NegroniContextKey])
+	user, ok := BasicAuthUser(ctx)
+	expect(t, ok, true)
+	expect(t, user, "user")
+	expect(t, BaggageFromContext(ctx)["k"], "v")
+	expect(t, NonceFromContext(ctx), "nonce")
+	expect(t, ErrGroupFromContext(ctx), contextKeys[errGroupKey])
+	expect(t, FlagEnabled(ctx, "flag"), true)
+	expect(t, LanguageFromContext(ctx), "fr")
+	path, ok := OriginalPath(ctx)
+	expect(t, ok, true)
+	expect(t, path, "/original")
+	expect(t, PhasesFromContext(ctx), contextKeys[phasesKey])
+	expect(t, ResponseLimitExceeded(ctx), true)
+	AddServerTiming(ctx, "db", time.Second)
+	expect(t, len(contextKeys[serverTimingKey].(*serverTimings).entries), 1)
+	expect(t, TenantFromContext(ctx), "acme")
+	expect(t, NegroniContextKey.String(), "negroni context value negroni")
+}
+
+func TestNegroniServeHTTPRaw(t *testing.T) {
+	var outer, inner http.ResponseWriter
+	response := httptest.NewRecorder()
+
+	child := New()
+	child.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		inner = rw
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		outer = rw
+		next(rw, r)
+	})
+	n.UseHandlerFunc(child.ServeHTTPRaw)
+
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, inner, outer)
+	expect(t, outer.(ResponseWriter).Status(), http.StatusAccepted)
+}
+
+func TestNegroniServeHTTPRaw_plainWriter(t *testing.T) {
+	var buff bytes.Buffer
+	response := httptest.NewRecorder()
+
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "", 0)
+	l.SetFormat("{{.Status}}")
+
+	n := New(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTPRaw(response, req)
+	expect(t, response.Code, http.StatusTeapot)
+	expect(t, strings.TrimSpace(buff.String()), "418")
+}
+
+func TestNegroniSetTransparentWriter(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("first\n"))
+		rw.(http.Flusher).Flush()
+		<-release
+		rw.Write([]byte("second\n"))
+	}))
+	defer backend.Close()
+
+	target, _ := url.Parse(backend.URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.FlushInterval = -1
+
+	wrapped := make(chan bool, 1)
+	n := New()
+	n.SetTransparentWriter(true)
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		_, ok := rw.(ResponseWriter)
+		wrapped <- ok
+		next(rw, r)
+	})
+	n.UseHandler(proxy)
+	server := httptest.NewServer(n)
+	defer server.Close()
+	defer close(release)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	// the first line arrives while the backend is still blocked
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	expect(t, err, nil)
+	expect(t, line, "first\n")
+	expect(t, <-wrapped, false)
+}
+
 func TestDetectAddress(t *testing.T) {
 	if detectAddress() != DefaultAddress {
 		t.Error("Expected the DefaultAddress")
@@ -175,6 +791,26 @@ func TestDetectAddress(t *testing.T) {
 	}
 }
 
+func TestDetectAddress_hostPort(t *testing.T) {
+	defer os.Unsetenv("HOST")
+	defer os.Unsetenv("PORT")
+
+	for _, tt := range []struct {
+		host, port, addr string
+	}{
+		{"", "", DefaultAddress},
+		{"", "9090", ":9090"},
+		{"127.0.0.1", "", "127.0.0.1:8080"},
+		{"127.0.0.1", "9090", "127.0.0.1:9090"},
+		{"::1", "9090", "[::1]:9090"},
+	} {
+		os.Setenv("HOST", tt.host)
+		os.Setenv("PORT", tt.port)
+		expect(t, detectAddress(), tt.addr)
+		expect(t, detectAddress(":6060"), ":6060")
+	}
+}
+
 func voidHTTPHandlerFunc(rw http.ResponseWriter, r *http.Request) {
 	// Do nothing
 }
@@ -205,3 +841,20 @@ func TestWrapFunc(t *testing.T) {
 
 	expect(t, response.Code, http.StatusOK)
 }
+
+func TestNegroniServeHTTP_doubleWriteHeader(t *testing.T) {
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	})
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.WriteHeader(http.StatusInternalServerError)
+		next(rw, r)
+	})
+
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, response.Code, http.StatusInternalServerError)
+}
@@ -0,0 +1,69 @@
+package negroni
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PathCanonicalize is a middleware handler that canonicalizes the request
+// URL's Path before calling the next handler, so that routing matches however
+// the path was written: percent-encoding is decoded (RawPath is dropped,
+// turning "/foo%2Fbar" into "/foo/bar"), repeated slashes are collapsed and
+// "." and ".." segments are resolved. A trailing slash is kept. Requests whose
+// path climbs above the root get a 400 and the next handler is not called.
+// The path as received is kept in the request context, see OriginalPath.
+type PathCanonicalize struct{}
+
+// NewPathCanonicalize returns a new instance of PathCanonicalize
+func NewPathCanonicalize() *PathCanonicalize {
+	return &PathCanonicalize{}
+}
+
+func (c *PathCanonicalize) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	p := r.URL.Path
+	if p == "" {
+		next(rw, r)
+		return
+	}
+	if escapesRoot(p) {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	if cleaned == p && r.URL.RawPath == "" {
+		next(rw, r)
+		return
+	}
+
+	r2 := r.WithContext(withOriginalPath(r.Context(), p))
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = cleaned
+	r2.URL.RawPath = ""
+	next(rw, r2)
+}
+
+// escapesRoot reports whether resolving the ".." segments of p climbs above
+// the root.
+func escapesRoot(p string) bool {
+	depth := 0
+	for _, segment := range strings.Split(p, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if depth == 0 {
+				return true
+			}
+			depth--
+		default:
+			depth++
+		}
+	}
+	return false
+}
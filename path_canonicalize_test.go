@@ -0,0 +1,45 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPathCanonicalize(t *testing.T) {
+	var path, original string
+	var rewritten bool
+	n := New(NewPathCanonicalize())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		original, rewritten = OriginalPath(r.Context())
+	})
+
+	for _, tt := range []struct {
+		url      string
+		code     int
+		path     string
+		original string
+	}{
+		{"/foo/bar", http.StatusOK, "/foo/bar", ""},
+		{"/foo//bar///baz/", http.StatusOK, "/foo/bar/baz/", "/foo//bar///baz/"},
+		{"/foo%2Fbar", http.StatusOK, "/foo/bar", "/foo/bar"},
+		{"/a/./b/../c", http.StatusOK, "/a/c", "/a/./b/../c"},
+		{"/a/..", http.StatusOK, "/", "/a/.."},
+		{"/../etc/passwd", http.StatusBadRequest, "", ""},
+		{"/a/../../etc/passwd", http.StatusBadRequest, "", ""},
+		{"/a/%2E%2E/%2E%2E/etc", http.StatusBadRequest, "", ""},
+	} {
+		path, original, rewritten = "", "", false
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000"+tt.url, nil)
+		n.ServeHTTP(recorder, req)
+
+		if recorder.Code != tt.code {
+			t.Errorf("Expected %d for %s, got %d", tt.code, tt.url, recorder.Code)
+		}
+		expect(t, path, tt.path)
+		expect(t, original, tt.original)
+		expect(t, rewritten, tt.original != "")
+	}
+}
@@ -0,0 +1,66 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+var phasesKey = &contextKey{"phases"}
+
+// RequestPhases holds the durations measured by PhaseTimer. FirstByte is the
+// time until the response header was written, zero if it never was. Total is
+// set once the rest of the chain returned, and also by a Logger below
+// PhaseTimer right before it logs.
+type RequestPhases struct {
+	FirstByte time.Duration
+	Total     time.Duration
+
+	start time.Time
+	clock clock
+}
+
+// end sets Total to the time elapsed so far.
+func (p *RequestPhases) end() {
+	p.Total = p.clock.since(p.start)
+}
+
+// PhaseTimer is a middleware handler that attributes the latency of requests
+// to phases, and stores them in the request context, see PhasesFromContext.
+// Place it before Logger for the phases to be logged, see
+// LoggerEntry.Phases.
+type PhaseTimer struct {
+	clock clock
+}
+
+// NewPhaseTimer returns a new instance of PhaseTimer
+func NewPhaseTimer() *PhaseTimer {
+	return &PhaseTimer{}
+}
+
+// SetNow sets the function used to measure the phases, time.Now by default.
+func (p *PhaseTimer) SetNow(now func() time.Time) {
+	p.clock = now
+}
+
+func (p *PhaseTimer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+
+	start := p.clock.now()
+	phases := &RequestPhases{start: start, clock: p.clock}
+	res.Before(func(ResponseWriter) {
+		phases.FirstByte = p.clock.since(start)
+	})
+	next(res, r.WithContext(context.WithValue(r.Context(), phasesKey, phases)))
+	phases.end()
+}
+
+// PhasesFromContext returns the phases measured by PhaseTimer for the request
+// the context belongs to, or nil if there are none.
+func PhasesFromContext(ctx context.Context) *RequestPhases {
+	phases, _ := ctx.Value(phasesKey).(*RequestPhases)
+	return phases
+}
@@ -0,0 +1,67 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPhaseTimer(t *testing.T) {
+	var phases *RequestPhases
+	n := New(NewPhaseTimer())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		phases = PhasesFromContext(r.Context())
+		time.Sleep(10 * time.Millisecond)
+		rw.Write([]byte("first"))
+		time.Sleep(10 * time.Millisecond)
+		rw.Write([]byte("second"))
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	refute(t, phases, (*RequestPhases)(nil))
+	expect(t, phases.FirstByte >= 10*time.Millisecond, true)
+	expect(t, phases.Total >= 20*time.Millisecond, true)
+	expect(t, phases.FirstByte <= phases.Total, true)
+}
+
+func TestPhaseTimer_noWrite(t *testing.T) {
+	var phases *RequestPhases
+	n := New(NewPhaseTimer())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		phases = PhasesFromContext(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, phases.FirstByte, time.Duration(0))
+	expect(t, PhasesFromContext(context.Background()), (*RequestPhases)(nil))
+}
+
+func TestPhaseTimer_logger(t *testing.T) {
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	p := NewPhaseTimer()
+	p.SetNow(clock)
+	l := NewLogger()
+	l.SetNow(clock)
+	buff := bytes.NewBufferString("")
+	l.ALogger = log.New(buff, "", 0)
+	l.SetFormat("{{.Status}} first byte {{.Phases.FirstByte}} total {{.Phases.Total}}")
+
+	n := New(p, l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		now = now.Add(10 * time.Millisecond)
+		rw.Write([]byte("first"))
+		now = now.Add(20 * time.Millisecond)
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, buff.String(), "200 first byte 10ms total 30ms\n")
+}
@@ -0,0 +1,34 @@
+package negroni
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// problem is the RFC 7807 problem details object written by ProblemJSON.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ProblemJSON writes an RFC 7807 problem details response with the given
+// status, title and detail. The type is "about:blank" and an empty title
+// defaults to the status text. The detail is omitted if empty.
+func ProblemJSON(rw http.ResponseWriter, status int, title, detail string) {
+	if title == "" {
+		title = http.StatusText(status)
+	}
+	body, _ := json.Marshal(problem{Type: "about:blank", Title: title, Status: status, Detail: detail})
+
+	rw.Header().Set("Content-Type", ProblemContentType)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(status)
+	rw.Write(body)
+}
@@ -0,0 +1,36 @@
+package negroni
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemJSON(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	rw := NewResponseWriter(recorder)
+
+	ProblemJSON(rw, http.StatusNotFound, "", "no such widget")
+
+	expect(t, recorder.Code, http.StatusNotFound)
+	expect(t, recorder.Header().Get("Content-Type"), ProblemContentType)
+	expect(t, rw.Size(), recorder.Body.Len())
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, len(got), 4)
+	expect(t, got["type"], "about:blank")
+	expect(t, got["title"], "Not Found")
+	expect(t, got["status"], float64(http.StatusNotFound))
+	expect(t, got["detail"], "no such widget")
+}
+
+func TestProblemJSON_noDetail(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ProblemJSON(recorder, http.StatusTeapot, "Brewing", "")
+
+	expect(t, recorder.Body.String(), `{"type":"about:blank","title":"Brewing","status":418}`)
+}
@@ -0,0 +1,154 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaStore counts the requests of Quota keys.
+type QuotaStore interface {
+	// Hit counts a request for key if it keeps the requests counted in the
+	// last window within limit. It returns the number of requests in the
+	// window, this one included whether it was counted or not, and when the
+	// quota resets: when a request would be counted again if this one was
+	// over the quota, or else when all the counted requests left the window.
+	Hit(key string, limit int, window time.Duration) (count int, reset time.Time)
+}
+
+// Quota is a middleware handler that allows at most Limit requests per key,
+// as returned by KeyFunc, in any rolling Window, such as an hour or a day.
+// The X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (in Unix
+// seconds) headers are added to the responses, and requests over the quota
+// get a 429 with a Retry-After header and are not counted. Requests whose key
+// is empty are not counted either. Window must be positive.
+type Quota struct {
+	Limit   int
+	Window  time.Duration
+	KeyFunc func(*http.Request) string
+	Store   QuotaStore
+
+	clock clock
+}
+
+// NewQuota returns a new instance of Quota counting in a MemoryQuotaStore.
+// It panics if window is not positive.
+func NewQuota(limit int, window time.Duration, keyFunc func(*http.Request) string) *Quota {
+	if window <= 0 {
+		panic("negroni: quota window must be positive")
+	}
+	return &Quota{
+		Limit:   limit,
+		Window:  window,
+		KeyFunc: keyFunc,
+		Store:   NewMemoryQuotaStore(),
+	}
+}
+
+// SetNow sets the function used to compute Retry-After, time.Now by default.
+func (q *Quota) SetNow(now func() time.Time) {
+	q.clock = now
+}
+
+func (q *Quota) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := q.KeyFunc(r)
+	if key == "" {
+		next(rw, r)
+		return
+	}
+
+	count, reset := q.Store.Hit(key, q.Limit, q.Window)
+	remaining := q.Limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	h := rw.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(q.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+	if count > q.Limit {
+		retryAfter := int64(reset.Sub(q.clock.now())/time.Second) + 1
+		h.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+	next(rw, r)
+}
+
+// MemoryQuotaStore is a QuotaStore counting in memory with a sliding window
+// counter: the requests of the current and previous fixed windows are
+// counted, and those of the previous window are weighted by how much of it is
+// still within the last window. This approximates a rolling window with two
+// counters per key whatever the limit. Keys without requests in the last two
+// windows are swept at most once per window, on a later Hit.
+type MemoryQuotaStore struct {
+	mu        sync.Mutex
+	counters  map[string]*quotaCounter
+	nextSweep time.Time
+	clock     clock
+}
+
+type quotaCounter struct {
+	// start is the start of the current fixed window
+	start      time.Time
+	prev, curr int
+}
+
+// NewMemoryQuotaStore returns a new, empty MemoryQuotaStore
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: make(map[string]*quotaCounter)}
+}
+
+// Hit implements QuotaStore
+func (s *MemoryQuotaStore) Hit(key string, limit int, window time.Duration) (int, time.Time) {
+	now := s.clock.now()
+	if window <= 0 {
+		return 1, now
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !now.Before(s.nextSweep) {
+		for k, c := range s.counters {
+			if !now.Before(c.start.Add(2 * window)) {
+				delete(s.counters, k)
+			}
+		}
+		s.nextSweep = now.Add(window)
+	}
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &quotaCounter{}
+		s.counters[key] = c
+	}
+	switch start := now.Truncate(window); {
+	case start.Equal(c.start):
+	case start.Equal(c.start.Add(window)):
+		c.start, c.prev, c.curr = start, c.curr, 0
+	default:
+		c.start, c.prev, c.curr = start, 0, 0
+	}
+
+	elapsed := now.Sub(c.start)
+	count := int(float64(c.prev)*float64(window-elapsed)/float64(window)) + c.curr + 1
+	if count <= limit {
+		c.curr++
+		return count, c.start.Add(2 * window)
+	}
+
+	// the request would be counted once the weighted previous requests drop
+	// below the room left, in this window or the next
+	switch {
+	case limit <= 0:
+		return count, c.start.Add(2 * window)
+	case c.curr < limit:
+		room := float64(limit-c.curr) / float64(c.prev)
+		return count, c.start.Add(window - time.Duration(float64(window)*room))
+	default:
+		room := float64(limit) / float64(c.curr)
+		return count, c.start.Add(2*window - time.Duration(float64(window)*room))
+	}
+}
@@ -0,0 +1,125 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := NewQuota(2, time.Hour, func(r *http.Request) string {
+		return r.Header.Get("X-Api-Key")
+	})
+	q.SetNow(func() time.Time { return now })
+	q.Store.(*MemoryQuotaStore).clock = func() time.Time { return now }
+
+	n := New(q)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	reset := strconv.FormatInt(now.Add(2*time.Hour).Unix(), 10)
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	req.Header.Set("X-Api-Key", "client")
+	for i, remaining := range []string{"1", "0"} {
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("Expected request %d to be allowed, got %d", i+1, recorder.Code)
+		}
+		expect(t, recorder.Header().Get("X-RateLimit-Limit"), "2")
+		expect(t, recorder.Header().Get("X-RateLimit-Remaining"), remaining)
+		expect(t, recorder.Header().Get("X-RateLimit-Reset"), reset)
+	}
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusTooManyRequests)
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "0")
+	expect(t, recorder.Header().Get("X-RateLimit-Reset"), strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+	expect(t, recorder.Header().Get("Retry-After"), "3601")
+
+	// other keys have their own quota
+	other, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	other.Header.Set("X-Api-Key", "other")
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, other)
+	expect(t, recorder.Code, http.StatusNoContent)
+
+	// the requests of the previous window still count at its end
+	now = now.Add(time.Hour)
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusTooManyRequests)
+
+	// the quota resets once they left the window
+	now = now.Add(time.Hour)
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "1")
+}
+
+func TestQuota_rolling(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	q := NewQuota(2, time.Hour, func(r *http.Request) string {
+		return "client"
+	})
+	q.SetNow(func() time.Time { return now })
+	q.Store.(*MemoryQuotaStore).clock = func() time.Time { return now }
+	n := New(q)
+
+	serve := func(at time.Duration) *httptest.ResponseRecorder {
+		now = start.Add(at)
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	expect(t, serve(0).Code, http.StatusOK)
+	expect(t, serve(30*time.Minute).Code, http.StatusOK)
+	// a fixed window would allow this one and the next
+	recorder := serve(61 * time.Minute)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "0")
+	expect(t, recorder.Header().Get("X-RateLimit-Reset"), strconv.FormatInt(start.Add(3*time.Hour).Unix(), 10))
+	// but the previous hour still weighs more than one request
+	recorder = serve(89 * time.Minute)
+	expect(t, recorder.Code, http.StatusTooManyRequests)
+	expect(t, recorder.Header().Get("X-RateLimit-Reset"), strconv.FormatInt(start.Add(90*time.Minute).Unix(), 10))
+	expect(t, recorder.Header().Get("Retry-After"), "61")
+	// rejected requests are not counted
+	expect(t, serve(91*time.Minute).Code, http.StatusOK)
+}
+
+func TestNewQuota_window(t *testing.T) {
+	defer func() {
+		refute(t, recover(), nil)
+	}()
+	NewQuota(1, 0, func(r *http.Request) string { return "client" })
+}
+
+func TestMemoryQuotaStore_sweep(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryQuotaStore()
+	s.clock = func() time.Time { return now }
+
+	s.Hit("a", 10, time.Minute)
+	s.Hit("b", 10, time.Minute)
+	expect(t, len(s.counters), 2)
+
+	now = now.Add(time.Minute)
+	s.Hit("b", 10, time.Minute)
+	expect(t, len(s.counters), 2)
+
+	// a has no requests in the last two windows
+	now = now.Add(time.Minute)
+	count, _ := s.Hit("b", 10, time.Minute)
+	expect(t, len(s.counters), 1)
+	expect(t, count, 2)
+}
@@ -0,0 +1,71 @@
+//go:build go1.20
+// +build go1.20
+
+package negroni
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ReadTimeout is a middleware handler that guards against slowloris-style
+// clients by giving every read of the request body a deadline of Timeout,
+// set on the connection with http.ResponseController. A read that stalls
+// fails, and if the handler did not write a response a 408 is written and
+// the connection closed. Writers that do not support read deadlines are
+// passed through unguarded.
+type ReadTimeout struct {
+	Timeout time.Duration
+}
+
+// NewReadTimeout returns a new instance of ReadTimeout.
+func NewReadTimeout(perReadTimeout time.Duration) *ReadTimeout {
+	return &ReadTimeout{Timeout: perReadTimeout}
+}
+
+func (t *ReadTimeout) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if t.Timeout <= 0 || r.Body == nil || r.Body == http.NoBody {
+		next(rw, r)
+		return
+	}
+
+	rc := http.NewResponseController(rw)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		next(rw, r)
+		return
+	}
+	defer rc.SetReadDeadline(time.Time{})
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+
+	body := &timeoutBody{ReadCloser: r.Body, rc: rc, timeout: t.Timeout}
+	r.Body = body
+	next(res, r)
+
+	if body.timedOut && !res.Written() {
+		res.Header().Set("Connection", "close")
+		http.Error(res, http.StatusText(http.StatusRequestTimeout), http.StatusRequestTimeout)
+	}
+}
+
+type timeoutBody struct {
+	io.ReadCloser
+	rc       *http.ResponseController
+	timeout  time.Duration
+	timedOut bool
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	b.rc.SetReadDeadline(time.Now().Add(b.timeout))
+	n, err := b.ReadCloser.Read(p)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		b.timedOut = true
+	}
+	return n, err
+}
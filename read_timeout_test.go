@@ -0,0 +1,76 @@
+//go:build go1.20
+// +build go1.20
+
+package negroni
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTimeout(t *testing.T) {
+	var readErr error
+	n := New(NewReadTimeout(50 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, readErr = ioutil.ReadAll(r.Body)
+	})
+	server := httptest.NewServer(n)
+	defer server.Close()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		// send part of the body, then stall
+		pw.Write([]byte("partial"))
+	}()
+
+	req, _ := http.NewRequest("POST", server.URL, pr)
+	req.ContentLength = 100
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	expect(t, res.StatusCode, http.StatusRequestTimeout)
+	refute(t, readErr, nil)
+}
+
+func TestReadTimeout_fastBody(t *testing.T) {
+	var body []byte
+	n := New(NewReadTimeout(time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	})
+	server := httptest.NewServer(n)
+	defer server.Close()
+
+	res, err := http.Post(server.URL, "text/plain", strings.NewReader("complete"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	expect(t, res.StatusCode, http.StatusOK)
+	expect(t, string(body), "complete")
+}
+
+func TestReadTimeout_unsupportedWriter(t *testing.T) {
+	called := false
+	n := New(NewReadTimeout(time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/", strings.NewReader("body"))
+	n.ServeHTTP(recorder, req)
+
+	expect(t, called, true)
+	expect(t, recorder.Code, http.StatusOK)
+}
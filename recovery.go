@@ -1,7 +1,11 @@
 package negroni
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -65,6 +69,8 @@ h1 {
 	nilRequestMessage = "Request is nil"
 )
 
+var recoveryKey = &contextKey{"recovery"}
+
 var panicHTMLTemplate = template.Must(template.New("PanicPage").Parse(panicHTML))
 
 // PanicInformation contains all
@@ -73,6 +79,9 @@ type PanicInformation struct {
 	RecoveredPanic interface{}
 	Stack          []byte
 	Request        *http.Request
+	// RequestBody holds the start of the request body when
+	// Recovery.CaptureBodySize is set.
+	RequestBody []byte
 }
 
 // StackAsString returns a printable version of the stack
@@ -137,6 +146,23 @@ type Recovery struct {
 	StackAll         bool
 	StackSize        int
 	Formatter        PanicFormatter
+	// CaptureBodySize is the number of request body bytes buffered before
+	// calling the next handler so they can be reported in PanicInformation.
+	// The handler still reads the full body. Zero disables capturing.
+	CaptureBodySize int
+	// Message is the body written after a panic when PrintStack is false.
+	Message string
+	// ContentType is the Content-Type of Message, used if the handler did not
+	// set one. Defaults to text/plain.
+	ContentType string
+	// ErrorMapper, if set, maps recovered values to an HTTP status. When it
+	// returns ok, that status is written with its status text instead of a
+	// 500, and the panic is neither logged nor passed to the handler funcs.
+	ErrorMapper func(recovered interface{}) (status int, ok bool)
+	// ProblemDetails makes the responses written when PrintStack is false, or
+	// for a status from ErrorMapper, RFC 7807 problem details (see ProblemJSON)
+	// instead of Message or the status text.
+	ProblemDetails bool
 
 	// Deprecated: Use PanicHandlerFunc instead to receive panic
 	// error with additional information (see PanicInformation)
@@ -152,58 +178,140 @@ func NewRecovery() *Recovery {
 		StackAll:   false,
 		StackSize:  1024 * 8,
 		Formatter:  &TextPanicFormatter{},
+		Message:    NoPrintStackBodyString,
 	}
 }
 
 func (rec *Recovery) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	var body []byte
+	if rec.CaptureBodySize > 0 && r != nil && r.Body != nil {
+		body = captureBody(r, rec.CaptureBodySize)
+	}
+
 	defer func() {
 		if err := recover(); err != nil {
-			rw.WriteHeader(http.StatusInternalServerError)
+			if rec.ErrorMapper != nil {
+				if status, ok := rec.ErrorMapper(err); ok {
+					if rec.ProblemDetails {
+						ProblemJSON(rw, status, "", "")
+					} else {
+						http.Error(rw, http.StatusText(status), status)
+					}
+					return
+				}
+			}
 
 			stack := make([]byte, rec.StackSize)
 			stack = stack[:runtime.Stack(stack, rec.StackAll)]
-			infos := &PanicInformation{RecoveredPanic: err, Request: r}
+			infos := &PanicInformation{RecoveredPanic: err, Request: r, RequestBody: body}
 
 			// PrintStack will write stack trace info to the ResponseWriter if set to true!
 			// If set to false it will respond with the standard response documented here https://httpstat.us/500
 			if rec.PrintStack {
+				rw.WriteHeader(http.StatusInternalServerError)
 				infos.Stack = stack
 				rec.Formatter.FormatPanicError(rw, r, infos)
 			} else {
-				if rw.Header().Get("Content-Type") == "" {
-					rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
-				}
-				fmt.Fprint(rw, NoPrintStackBodyString)
-			}
-
-			if rec.LogStack {
-				rec.Logger.Printf(panicText, err, stack)
+				rec.writeMessage(rw)
 			}
 
-			if rec.ErrorHandlerFunc != nil {
-				func() {
-					defer func() {
-						if err := recover(); err != nil {
-							rec.Logger.Printf("provided ErrorHandlerFunc panic'd: %s, trace:\n%s", err, debug.Stack())
-							rec.Logger.Printf("%s\n", debug.Stack())
-						}
-					}()
-					rec.ErrorHandlerFunc(err)
-				}()
-			}
-			if rec.PanicHandlerFunc != nil {
-				func() {
-					defer func() {
-						if err := recover(); err != nil {
-							rec.Logger.Printf("provided PanicHandlerFunc panic'd: %s, trace:\n%s", err, debug.Stack())
-							rec.Logger.Printf("%s\n", debug.Stack())
-						}
-					}()
-					rec.PanicHandlerFunc(infos)
-				}()
-			}
+			rec.report(infos, stack)
 		}
 	}()
 
+	if r != nil {
+		// for SafeGo
+		r = r.WithContext(context.WithValue(r.Context(), recoveryKey, rec))
+	}
 	next(rw, r)
 }
+
+// report logs a recovered panic and passes it to the handler funcs.
+func (rec *Recovery) report(infos *PanicInformation, stack []byte) {
+	err := infos.RecoveredPanic
+	if rec.LogStack {
+		rec.Logger.Printf(panicText, err, stack)
+	}
+
+	if rec.ErrorHandlerFunc != nil {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					rec.Logger.Printf("provided ErrorHandlerFunc panic'd: %s, trace:\n%s", err, debug.Stack())
+					rec.Logger.Printf("%s\n", debug.Stack())
+				}
+			}()
+			rec.ErrorHandlerFunc(err)
+		}()
+	}
+	if rec.PanicHandlerFunc != nil {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					rec.Logger.Printf("provided PanicHandlerFunc panic'd: %s, trace:\n%s", err, debug.Stack())
+					rec.Logger.Printf("%s\n", debug.Stack())
+				}
+			}()
+			rec.PanicHandlerFunc(infos)
+		}()
+	}
+}
+
+// SafeGo runs fn in a new goroutine, recovering from its panics so they do not
+// crash the process. A panic is reported like one of the request goroutine by
+// the Recovery serving r, which logs it and calls its handler funcs, with the
+// stack of the goroutine in PanicInformation.Stack; nothing is written to the
+// response, which may be long gone. Without a Recovery in the chain the panic
+// is only logged.
+func SafeGo(r *http.Request, fn func()) {
+	rec, _ := r.Context().Value(recoveryKey).(*Recovery)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				if rec == nil {
+					log.Printf(panicText, err, debug.Stack())
+					return
+				}
+				stack := make([]byte, rec.StackSize)
+				stack = stack[:runtime.Stack(stack, rec.StackAll)]
+				rec.report(&PanicInformation{RecoveredPanic: err, Stack: stack, Request: r}, stack)
+			}
+		}()
+		fn()
+	}()
+}
+
+func (rec *Recovery) writeMessage(rw http.ResponseWriter) {
+	if rec.ProblemDetails {
+		ProblemJSON(rw, http.StatusInternalServerError, "", "")
+		return
+	}
+	if rw.Header().Get("Content-Type") == "" {
+		contentType := rec.ContentType
+		if contentType == "" {
+			contentType = "text/plain; charset=utf-8"
+		}
+		rw.Header().Set("Content-Type", contentType)
+	}
+	// headers must be set before the status is written to be sent
+	rw.WriteHeader(http.StatusInternalServerError)
+	message := rec.Message
+	if message == "" {
+		message = NoPrintStackBodyString
+	}
+	fmt.Fprint(rw, message)
+}
+
+// captureBody reads up to max bytes of the request body and replaces the body
+// so the next handlers read the captured bytes followed by the remainder.
+func captureBody(r *http.Request, max int) []byte {
+	// a read error is left for the handler to hit on the remainder
+	body, _ := ioutil.ReadAll(io.LimitReader(r.Body, int64(max)))
+	r.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	return body
+}
+
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
@@ -2,12 +2,15 @@ package negroni
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRecovery(t *testing.T) {
@@ -172,3 +175,197 @@ func TestRecovery_HTMLFormatter(t *testing.T) {
 	expect(t, recorder.Header().Get("Content-Type"), "text/html; charset=utf-8")
 	refute(t, recorder.Body.Len(), 0)
 }
+
+func TestRecovery_captureBody(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var infos *PanicInformation
+	var read string
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBuffer([]byte{}), "[negroni] ", 0)
+	rec.CaptureBodySize = 5
+	rec.PanicHandlerFunc = func(i *PanicInformation) {
+		infos = i
+	}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		read = string(b)
+		panic("here is a panic!")
+	}))
+
+	req, _ := http.NewRequest("POST", "http://localhost:3003/somePath", strings.NewReader("hello world"))
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, read, "hello world")
+	expect(t, string(infos.RequestBody), "hello")
+}
+
+func TestRecovery_captureBodyDisabled(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var infos *PanicInformation
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBuffer([]byte{}), "[negroni] ", 0)
+	rec.PanicHandlerFunc = func(i *PanicInformation) {
+		infos = i
+	}
+
+	body := strings.NewReader("hello world")
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// the body must not have been touched
+		expect(t, body.Len(), len("hello world"))
+		panic("here is a panic!")
+	}))
+
+	req, _ := http.NewRequest("POST", "http://localhost:3003/somePath", body)
+	n.ServeHTTP(recorder, req)
+	expect(t, len(infos.RequestBody), 0)
+}
+
+func TestRecovery_customMessage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBuffer([]byte{}), "[negroni] ", 0)
+	rec.PrintStack = false
+	rec.Message = `{"error":"internal"}`
+	rec.ContentType = "application/json"
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+
+	res := recorder.Result()
+	expect(t, res.StatusCode, http.StatusInternalServerError)
+	expect(t, res.Header.Get("Content-Type"), "application/json")
+	expect(t, recorder.Body.String(), `{"error":"internal"}`)
+}
+
+func TestRecovery_defaultMessage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	rec := &Recovery{Logger: log.New(bytes.NewBuffer([]byte{}), "[negroni] ", 0)}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, recorder.Result().Header.Get("Content-Type"), "text/plain; charset=utf-8")
+	expect(t, recorder.Body.String(), NoPrintStackBodyString)
+}
+
+func TestRecovery_problemDetails(t *testing.T) {
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBuffer([]byte{}), "[negroni] ", 0)
+	rec.PrintStack = false
+	rec.ProblemDetails = true
+	rec.ErrorMapper = func(recovered interface{}) (int, bool) {
+		return http.StatusForbidden, recovered == errForbidden
+	}
+
+	var value interface{}
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic(value)
+	}))
+
+	recorder := httptest.NewRecorder()
+	value = "here is a panic!"
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, recorder.Header().Get("Content-Type"), ProblemContentType)
+	expect(t, recorder.Body.String(), `{"type":"about:blank","title":"Internal Server Error","status":500}`)
+
+	recorder = httptest.NewRecorder()
+	value = errForbidden
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusForbidden)
+	expect(t, recorder.Body.String(), `{"type":"about:blank","title":"Forbidden","status":403}`)
+}
+
+var errForbidden = errors.New("forbidden")
+
+func TestRecovery_errorMapper(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	panicHandlerCalled := false
+
+	rec := NewRecovery()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+	rec.PanicHandlerFunc = func(i *PanicInformation) {
+		panicHandlerCalled = true
+	}
+	rec.ErrorMapper = func(recovered interface{}) (int, bool) {
+		if recovered == errForbidden {
+			return http.StatusForbidden, true
+		}
+		return 0, false
+	}
+
+	var value interface{}
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic(value)
+	}))
+
+	recorder := httptest.NewRecorder()
+	value = errForbidden
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusForbidden)
+	expect(t, strings.TrimSpace(recorder.Body.String()), "Forbidden")
+	expect(t, buff.Len(), 0)
+	expect(t, panicHandlerCalled, false)
+
+	recorder = httptest.NewRecorder()
+	value = "unmapped"
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, panicHandlerCalled, true)
+}
+
+func TestSafeGo(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	reported := make(chan *PanicInformation, 1)
+	rec := NewRecovery()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+	rec.PanicHandlerFunc = func(info *PanicInformation) {
+		reported <- info
+	}
+
+	n := New(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		SafeGo(r, func() {
+			panic("spawned")
+		})
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3003/jobs", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusAccepted)
+
+	select {
+	case info := <-reported:
+		expect(t, info.RecoveredPanic, "spawned")
+		expect(t, info.RequestDescription(), "GET /jobs")
+		refute(t, len(info.Stack), 0)
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] PANIC: spawned"), true)
+}
@@ -0,0 +1,115 @@
+package negroni
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+)
+
+// RequestRecorder is a middleware handler that saves a Sample fraction of the
+// requests, between 0 and 1, as JSON files in Dir, so production issues can be
+// reproduced with ReplayRequest. The method, URL, headers and up to MaxBodySize
+// bytes of the body are recorded once the rest of the chain has returned, or
+// panicked, so requests crashing a handler are kept too. The body is captured
+// as the handler reads it, so the handler still gets all of it, but the record
+// only holds what was read. Records contain credentials such as Authorization
+// headers and are only readable by their owner.
+type RequestRecorder struct {
+	Dir         string
+	Sample      float64
+	MaxBodySize int
+	Logger      ALogger
+}
+
+// NewRequestRecorder returns a new instance of RequestRecorder recording
+// bodies up to 1MB.
+func NewRequestRecorder(dir string, sample float64) *RequestRecorder {
+	return &RequestRecorder{
+		Dir:         dir,
+		Sample:      sample,
+		MaxBodySize: 1 << 20,
+		Logger:      log.New(os.Stdout, "[negroni] ", 0),
+	}
+}
+
+// recordedRequest is the JSON document written by RequestRecorder.
+type recordedRequest struct {
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	Header        http.Header `json:"header"`
+	Body          []byte      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+}
+
+func (rr *RequestRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if rr.Sample <= 0 || (rr.Sample < 1 && rand.Float64() >= rr.Sample) {
+		next(rw, r)
+		return
+	}
+
+	body := &cappedBuffer{max: rr.MaxBodySize}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = &replayBody{Reader: io.TeeReader(r.Body, body), Closer: r.Body}
+	}
+	// the handlers may change the URL and headers
+	record := recordedRequest{Method: r.Method, URL: requestURL(r), Header: make(http.Header, len(r.Header))}
+	for k, v := range r.Header {
+		record.Header[k] = append([]string(nil), v...)
+	}
+	defer func() {
+		record.Body, record.BodyTruncated = body.Bytes(), body.truncated
+		if err := rr.save(&record); err != nil {
+			rr.Logger.Printf("recording %s %s failed: %v", r.Method, r.URL.Path, err)
+		}
+	}()
+	next(rw, r)
+}
+
+func (rr *RequestRecorder) save(record *recordedRequest) error {
+	f, err := ioutil.TempFile(rr.Dir, "request-*.json")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(record); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// requestURL returns the absolute URL of the server request r.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// ReplayRequest loads a request saved by RequestRecorder at path. It can be
+// served by a Negroni instance, or sent to a server once its URL is pointed
+// at it.
+func ReplayRequest(path string) (*http.Request, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var record recordedRequest
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequest(record.Method, record.URL, bytes.NewReader(record.Body))
+	if err != nil {
+		return nil, err
+	}
+	if record.Header != nil {
+		r.Header = record.Header
+	}
+	return r, nil
+}
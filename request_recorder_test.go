@@ -0,0 +1,95 @@
+package negroni
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "negroni-recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var handlerBody string
+	n := New(NewRequestRecorder(dir, 1))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		handlerBody = string(b)
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://example.com/orders?id=7", strings.NewReader("payload"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Add("X-Multi", "a")
+	req.Header.Add("X-Multi", "b")
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Code, http.StatusAccepted)
+	expect(t, handlerBody, "payload")
+
+	files, _ := filepath.Glob(filepath.Join(dir, "request-*.json"))
+	expect(t, len(files), 1)
+	replayed, err := ReplayRequest(files[0])
+	expect(t, err, nil)
+	expect(t, replayed.Method, "POST")
+	expect(t, replayed.URL.String(), "http://example.com/orders?id=7")
+	expect(t, replayed.Host, "example.com")
+	expect(t, replayed.Header.Get("Content-Type"), "text/plain")
+	expect(t, strings.Join(replayed.Header["X-Multi"], ","), "a,b")
+	b, _ := ioutil.ReadAll(replayed.Body)
+	expect(t, string(b), "payload")
+}
+
+func TestRequestRecorder_notSampled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "negroni-recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	n := New(NewRequestRecorder(dir, 0))
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	files, _ := ioutil.ReadDir(dir)
+	expect(t, len(files), 0)
+}
+
+func TestRequestRecorder_panic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "negroni-recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recovery := NewRecovery()
+	recovery.Logger = log.New(ioutil.Discard, "", 0)
+	recovery.PrintStack = false
+	n := New(recovery, NewRequestRecorder(dir, 1))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://example.com/crash", strings.NewReader("payload"))
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "request-*.json"))
+	expect(t, len(files), 1)
+	replayed, err := ReplayRequest(files[0])
+	expect(t, err, nil)
+	expect(t, replayed.URL.String(), "http://example.com/crash")
+	b, _ := ioutil.ReadAll(replayed.Body)
+	expect(t, string(b), "payload")
+}
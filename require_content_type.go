@@ -0,0 +1,45 @@
+package negroni
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// RequireContentType is a middleware handler that rejects POST, PUT and PATCH
+// requests with a body whose Content-Type is not one of Types with a 415
+// Unsupported Media Type. Parameters such as "; charset=utf-8" are ignored and
+// the comparison is case insensitive. Requests without a body pass through.
+type RequireContentType struct {
+	Types []string
+}
+
+// NewRequireContentType returns a new instance of RequireContentType
+func NewRequireContentType(types ...string) *RequireContentType {
+	return &RequireContentType{Types: types}
+}
+
+func (c *RequireContentType) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !hasBodyMethod(r.Method) || r.ContentLength == 0 || c.allowed(r.Header.Get("Content-Type")) {
+		next(rw, r)
+		return
+	}
+	http.Error(rw, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+}
+
+func (c *RequireContentType) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range c.Types {
+		if strings.EqualFold(mediaType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBodyMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
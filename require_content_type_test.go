@@ -0,0 +1,40 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireContentType(t *testing.T) {
+	n := New(NewRequireContentType("application/json", "application/x-www-form-urlencoded"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, tt := range []struct {
+		method      string
+		body        string
+		contentType string
+		code        int
+	}{
+		{"POST", "{}", "application/json", http.StatusNoContent},
+		{"PUT", "{}", "Application/JSON; charset=utf-8", http.StatusNoContent},
+		{"PATCH", "a=b", "application/x-www-form-urlencoded", http.StatusNoContent},
+		{"POST", "<a/>", "text/xml", http.StatusUnsupportedMediaType},
+		{"POST", "{}", "", http.StatusUnsupportedMediaType},
+		{"PUT", "{}", "invalid;;", http.StatusUnsupportedMediaType},
+		{"POST", "", "text/xml", http.StatusNoContent},
+		{"GET", "", "text/xml", http.StatusNoContent},
+		{"DELETE", "<a/>", "text/xml", http.StatusNoContent},
+	} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, "http://localhost:3000/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Code, tt.code)
+	}
+}
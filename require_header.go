@@ -0,0 +1,38 @@
+package negroni
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// secureCompare reports whether given equals expected in constant time.
+// Both are hashed first so not even their lengths leak through timing.
+func secureCompare(given, expected string) bool {
+	g := sha256.Sum256([]byte(given))
+	e := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(g[:], e[:]) == 1
+}
+
+// RequireHeader is a middleware handler that only lets requests through whose
+// header Name is Value, such as a secret shared between internal services.
+// Other requests get a 403 and the next handler is not called. The values are
+// compared in constant time.
+type RequireHeader struct {
+	Name  string
+	Value string
+}
+
+// NewRequireHeader returns a new instance of RequireHeader
+func NewRequireHeader(name, value string) *RequireHeader {
+	return &RequireHeader{Name: name, Value: value}
+}
+
+func (h *RequireHeader) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	values, ok := r.Header[http.CanonicalHeaderKey(h.Name)]
+	if !ok || len(values) != 1 || !secureCompare(values[0], h.Value) {
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	next(rw, r)
+}
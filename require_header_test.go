@@ -0,0 +1,44 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireHeader(t *testing.T) {
+	n := New()
+	n.Use(NewRequireHeader("X-Internal-Secret", "s3cret"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for value, code := range map[string]int{
+		"s3cret":  http.StatusNoContent,
+		"s3cre":   http.StatusForbidden,
+		"S3CRET":  http.StatusForbidden,
+		"s3cret ": http.StatusForbidden,
+	} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		req.Header.Set("X-Internal-Secret", value)
+		n.ServeHTTP(recorder, req)
+		if recorder.Code != code {
+			t.Errorf("Header %q: expected %d, got %d", value, code, recorder.Code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusForbidden)
+}
+
+func TestSecureCompare(t *testing.T) {
+	expect(t, secureCompare("s3cret", "s3cret"), true)
+	expect(t, secureCompare("", ""), true)
+	expect(t, secureCompare("s3creT", "s3cret"), false)
+	expect(t, secureCompare("s3c", "s3cret"), false)
+	expect(t, secureCompare("s3cret-and-more", "s3cret"), false)
+	expect(t, secureCompare("", "s3cret"), false)
+}
@@ -0,0 +1,49 @@
+package negroni
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseBuffer is an http.ResponseWriter recording the response in memory
+// so it can be inspected or replayed to other writers.
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header)}
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(s int) {
+	if b.status == 0 {
+		b.status = s
+	}
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// writeTo replays the recorded response to rw. It does not modify the buffer,
+// so the same response can be replayed to several writers concurrently.
+func (b *responseBuffer) writeTo(rw http.ResponseWriter) {
+	header := rw.Header()
+	for k, v := range b.header {
+		header[k] = append([]string(nil), v...)
+	}
+	if b.status == 0 {
+		return
+	}
+	rw.WriteHeader(b.status)
+	rw.Write(b.body.Bytes())
+}
@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"hash"
+	"net/http"
+)
+
+// ResponseHash is a middleware handler that computes a checksum of response
+// bodies as they are written, without altering them. Every request gets a
+// hash from New, such as sha256.New, since a hash.Hash cannot be shared
+// between concurrent requests. OnComplete is called with the digest once the
+// rest of the chain has returned.
+type ResponseHash struct {
+	New        func() hash.Hash
+	OnComplete func(sum []byte)
+}
+
+// NewResponseHash returns a new instance of ResponseHash
+func NewResponseHash(newHash func() hash.Hash, onComplete func(sum []byte)) *ResponseHash {
+	return &ResponseHash{New: newHash, OnComplete: onComplete}
+}
+
+func (h *ResponseHash) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	hw := &hashResponseWriter{ResponseWriter: res, hash: h.New()}
+	next(hw, r)
+	h.OnComplete(hw.hash.Sum(nil))
+}
+
+type hashResponseWriter struct {
+	ResponseWriter
+	hash hash.Hash
+}
+
+func (hw *hashResponseWriter) Write(b []byte) (int, error) {
+	n, err := hw.ResponseWriter.Write(b)
+	// only the bytes actually sent are hashed
+	hw.hash.Write(b[:n])
+	return n, err
+}
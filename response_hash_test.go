@@ -0,0 +1,27 @@
+package negroni
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseHash(t *testing.T) {
+	var sum []byte
+	n := New(NewResponseHash(sha256.New, func(s []byte) {
+		sum = s
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("hello, "))
+		rw.Write([]byte("world"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expected := sha256.Sum256([]byte("hello, world"))
+	expect(t, recorder.Body.String(), "hello, world")
+	expect(t, string(sum), string(expected[:]))
+}
@@ -0,0 +1,64 @@
+package negroni
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrResponseTooLarge is returned by writes dropped by ResponseLimit.
+var ErrResponseTooLarge = errors.New("negroni: response body exceeds limit")
+
+var responseLimitKey = &contextKey{"response-limit"}
+
+// ResponseLimit is a middleware handler that caps the size of response bodies.
+// Once Max bytes have been written the response is truncated: the part of a
+// write that exceeds the limit is dropped, the write returns
+// ErrResponseTooLarge and so do all later writes. The connection is left
+// open. Whether the limit was hit can be checked with ResponseLimitExceeded.
+type ResponseLimit struct {
+	Max int64
+}
+
+// NewResponseLimit returns a new instance of ResponseLimit
+func NewResponseLimit(max int64) *ResponseLimit {
+	return &ResponseLimit{Max: max}
+}
+
+func (l *ResponseLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	lw := &limitResponseWriter{ResponseWriter: res, remaining: l.Max}
+	next(lw, r.WithContext(context.WithValue(r.Context(), responseLimitKey, lw)))
+}
+
+// ResponseLimitExceeded reports whether the response of the request the
+// context belongs to was truncated by ResponseLimit.
+func ResponseLimitExceeded(ctx context.Context) bool {
+	lw, ok := ctx.Value(responseLimitKey).(*limitResponseWriter)
+	return ok && lw.exceeded
+}
+
+type limitResponseWriter struct {
+	ResponseWriter
+	remaining int64
+	exceeded  bool
+}
+
+func (lw *limitResponseWriter) Write(b []byte) (int, error) {
+	if int64(len(b)) <= lw.remaining {
+		n, err := lw.ResponseWriter.Write(b)
+		lw.remaining -= int64(n)
+		return n, err
+	}
+
+	lw.exceeded = true
+	n, err := lw.ResponseWriter.Write(b[:lw.remaining])
+	lw.remaining -= int64(n)
+	if err == nil {
+		err = ErrResponseTooLarge
+	}
+	return n, err
+}
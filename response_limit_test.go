@@ -0,0 +1,50 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseLimit(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var errs []error
+	exceeded := false
+
+	n := New()
+	n.Use(NewResponseLimit(8))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		for _, s := range []string{"hello", " world", "!"} {
+			_, err := rw.Write([]byte(s))
+			errs = append(errs, err)
+		}
+		exceeded = ResponseLimitExceeded(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Body.String(), "hello wo")
+	expect(t, errs[0], nil)
+	expect(t, errs[1], ErrResponseTooLarge)
+	expect(t, errs[2], ErrResponseTooLarge)
+	expect(t, exceeded, true)
+}
+
+func TestResponseLimit_underLimit(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	exceeded := true
+
+	n := New()
+	n.Use(NewResponseLimit(8))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("12345678"))
+		exceeded = ResponseLimitExceeded(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Body.String(), "12345678")
+	expect(t, exceeded, false)
+}
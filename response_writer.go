@@ -46,9 +46,29 @@ type responseWriter struct {
 	status      int
 	size        int
 	beforeFuncs []beforeFunc
+	afterFuncs  []func()
+
+	beforeWriteFuncs []func(status int) int
+
+	// set by deadline middleware, see markTimedOut
+	timedOutIn string
+	isTimedOut bool
 }
 
 func (rw *responseWriter) WriteHeader(s int) {
+	if rw.Written() {
+		// the first status sticks, repeated calls would only make net/http
+		// complain about a superfluous WriteHeader
+		return
+	}
+	if s >= 100 && s < 200 && s != http.StatusSwitchingProtocols {
+		// informational headers may precede the final one
+		rw.ResponseWriter.WriteHeader(s)
+		return
+	}
+	for _, fn := range rw.beforeWriteFuncs {
+		s = fn(s)
+	}
 	rw.status = s
 	rw.callBefore()
 	rw.ResponseWriter.WriteHeader(s)
@@ -64,6 +84,23 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Reset makes the ResponseWriter wrap w with all its state cleared, as if it
+// was just returned by NewResponseWriter, so it can be reused across serves.
+// Writers returned by NewResponseWriter implement it through
+// interface{ Reset(http.ResponseWriter) }. Whether http.CloseNotifier is
+// implemented is decided on creation, so w should support the same optional
+// interfaces as the ResponseWriter it replaces.
+func (rw *responseWriter) Reset(w http.ResponseWriter) {
+	rw.ResponseWriter = w
+	rw.status = 0
+	rw.size = 0
+	rw.beforeFuncs = nil
+	rw.afterFuncs = nil
+	rw.beforeWriteFuncs = nil
+	rw.isTimedOut = false
+	rw.timedOutIn = ""
+}
+
 func (rw *responseWriter) Status() int {
 	return rw.status
 }
@@ -80,6 +117,47 @@ func (rw *responseWriter) Before(before func(ResponseWriter)) {
 	rw.beforeFuncs = append(rw.beforeFuncs, before)
 }
 
+// BeforeWrite registers fn to be called when the status is about to be
+// written, explicitly or by the first Write, with the intended status. The
+// status fn returns is written instead, and fn may also change the headers.
+// Functions are called in the order they were registered, each getting the
+// status returned by the previous one, and before the Before functions, which
+// see the final status. Writers returned by NewResponseWriter implement it
+// through interface{ BeforeWrite(func(int) int) }.
+func (rw *responseWriter) BeforeWrite(fn func(status int) int) {
+	rw.beforeWriteFuncs = append(rw.beforeWriteFuncs, fn)
+}
+
+// After registers a function to be called once the middleware chain has
+// finished serving the request, so the response has been fully written.
+// Functions are called in the reverse order they were registered, like
+// Before. They are not called if the chain panics, and a panicking function
+// prevents the remaining ones from running. Writers returned by
+// NewResponseWriter implement it through interface{ After(func()) }; the
+// functions are called by Negroni.ServeHTTP.
+func (rw *responseWriter) After(after func()) {
+	rw.afterFuncs = append(rw.afterFuncs, after)
+}
+
+func (rw *responseWriter) markTimedOut(handler string) {
+	rw.isTimedOut = true
+	rw.timedOutIn = handler
+}
+
+func (rw *responseWriter) timedOut() (bool, string) {
+	return rw.isTimedOut, rw.timedOutIn
+}
+
+func (rw *responseWriter) countSize(n int) {
+	rw.size += n
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, allowing
+// http.ResponseController to reach it.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
 	if !ok {
@@ -94,6 +172,12 @@ func (rw *responseWriter) callBefore() {
 	}
 }
 
+func (rw *responseWriter) callAfter() {
+	for i := len(rw.afterFuncs) - 1; i >= 0; i-- {
+		rw.afterFuncs[i]()
+	}
+}
+
 func (rw *responseWriter) Flush() {
 	flusher, ok := rw.ResponseWriter.(http.Flusher)
 	if ok {
@@ -2,6 +2,7 @@ package negroni
 
 import (
 	"bufio"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -185,3 +186,135 @@ func TestResponseWriter_Flush_marksWritten(t *testing.T) {
 	expect(t, rw.Status(), http.StatusOK)
 	expect(t, rw.Written(), true)
 }
+
+func TestResponseWriterWriteHeaderTwice(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+	calls := 0
+
+	rw.Before(func(ResponseWriter) {
+		calls++
+	})
+	rw.WriteHeader(http.StatusInternalServerError)
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte("body"))
+
+	expect(t, rec.Code, http.StatusInternalServerError)
+	expect(t, rw.Status(), http.StatusInternalServerError)
+	expect(t, rec.Body.String(), "body")
+	expect(t, calls, 1)
+}
+
+func TestResponseWriterInformationalHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+
+	rw.WriteHeader(http.StatusContinue)
+	expect(t, rw.Written(), false)
+
+	rw.WriteHeader(http.StatusAccepted)
+	expect(t, rw.Status(), http.StatusAccepted)
+}
+
+func TestResponseWriterReset(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+	calls := 0
+
+	rw.Before(func(ResponseWriter) {
+		calls++
+	})
+	rw.WriteHeader(http.StatusNotFound)
+	rw.Write([]byte("not found"))
+
+	rec2 := httptest.NewRecorder()
+	resetter, ok := rw.(interface{ Reset(http.ResponseWriter) })
+	expect(t, ok, true)
+	resetter.Reset(rec2)
+
+	expect(t, rw.Status(), 0)
+	expect(t, rw.Size(), 0)
+	expect(t, rw.Written(), false)
+
+	rw.Write([]byte("ok"))
+	expect(t, rw.Status(), http.StatusOK)
+	expect(t, rw.Size(), 2)
+	expect(t, rec2.Code, http.StatusOK)
+	expect(t, rec2.Body.String(), "ok")
+	expect(t, rec.Body.String(), "not found")
+	expect(t, calls, 1)
+}
+
+func TestResponseWriterTrailers(t *testing.T) {
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, wrapped := rw.(ResponseWriter)
+		expect(t, wrapped, true)
+
+		rw.Header().Set("Trailer", "Grpc-Status")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte("body"))
+		rw.(http.Flusher).Flush()
+		rw.Header().Set("Grpc-Status", "0")
+		rw.Header().Set(http.TrailerPrefix+"Grpc-Message", "ok")
+	})
+	server := httptest.NewServer(n)
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	expect(t, err, nil)
+	expect(t, string(body), "body")
+	expect(t, res.Header.Get("Grpc-Status"), "")
+	expect(t, res.Trailer.Get("Grpc-Status"), "0")
+	expect(t, res.Trailer.Get("Grpc-Message"), "ok")
+}
+
+func TestResponseWriterBeforeWriteHook(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+	bw, ok := rw.(interface{ BeforeWrite(func(int) int) })
+	expect(t, ok, true)
+
+	result := ""
+	bw.BeforeWrite(func(status int) int {
+		result += "first"
+		expect(t, status, http.StatusTeapot)
+		rw.Header().Set("X-Rewritten", "yes")
+		return http.StatusBadRequest
+	})
+	bw.BeforeWrite(func(status int) int {
+		result += "second"
+		expect(t, status, http.StatusBadRequest)
+		return status
+	})
+	rw.Before(func(w ResponseWriter) {
+		result += "before"
+		expect(t, w.Status(), http.StatusBadRequest)
+	})
+
+	rw.WriteHeader(http.StatusTeapot)
+	rw.Write([]byte("body"))
+
+	expect(t, result, "firstsecondbefore")
+	expect(t, rec.Code, http.StatusBadRequest)
+	expect(t, rw.Status(), http.StatusBadRequest)
+	expect(t, rec.Header().Get("X-Rewritten"), "yes")
+}
+
+func TestResponseWriterBeforeWriteHook_implicit(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+	rw.(interface{ BeforeWrite(func(int) int) }).BeforeWrite(func(status int) int {
+		expect(t, status, http.StatusOK)
+		return http.StatusCreated
+	})
+
+	rw.Write([]byte("created"))
+	expect(t, rec.Code, http.StatusCreated)
+}
@@ -0,0 +1,90 @@
+package negroni
+
+import "net/http"
+
+// Retry is a middleware handler that calls the next handler again, up to Max
+// more times, while its response has a status for which RetryOn returns true,
+// such as a 503 from a flaky backend. Responses are buffered so failed
+// attempts can be discarded, headers included, and only the last one is sent.
+// A response flushed by the handler is streamed to the client instead, and is
+// not retried. Only GET and HEAD requests, which are idempotent and have no
+// body to replay, are retried.
+type Retry struct {
+	Max     int
+	RetryOn func(status int) bool
+}
+
+// NewRetry returns a new instance of Retry. A nil retryOn retries 502, 503
+// and 504 responses.
+func NewRetry(max int, retryOn func(status int) bool) *Retry {
+	if retryOn == nil {
+		retryOn = func(status int) bool {
+			switch status {
+			case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				return true
+			}
+			return false
+		}
+	}
+	return &Retry{Max: max, RetryOn: retryOn}
+}
+
+func (rt *Retry) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		next(rw, r)
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		buf := &retryBuffer{responseBuffer: newResponseBuffer(), rw: rw}
+		// a fresh writer, so the Before functions of failed attempts do not run
+		next(NewResponseWriter(buf), r)
+		if buf.streaming {
+			return
+		}
+		status := buf.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if attempt >= rt.Max || !rt.RetryOn(status) || r.Context().Err() != nil {
+			buf.writeTo(rw)
+			return
+		}
+	}
+}
+
+// retryBuffer is a responseBuffer switching to writing through to rw once
+// flushed.
+type retryBuffer struct {
+	*responseBuffer
+	rw        http.ResponseWriter
+	streaming bool
+}
+
+func (b *retryBuffer) WriteHeader(s int) {
+	if b.streaming {
+		b.rw.WriteHeader(s)
+		return
+	}
+	b.responseBuffer.WriteHeader(s)
+}
+
+func (b *retryBuffer) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.rw.Write(p)
+	}
+	return b.responseBuffer.Write(p)
+}
+
+func (b *retryBuffer) Flush() {
+	if !b.streaming {
+		b.streaming = true
+		if b.status == 0 {
+			b.status = http.StatusOK
+		}
+		b.writeTo(b.rw)
+	}
+	if flusher, ok := b.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,36 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAfter is a middleware handler that sets a Retry-After header of
+// Duration, rounded up to whole seconds, on 429 and 503 responses that do not
+// have one, so clients back off before trying again.
+type RetryAfter struct {
+	Duration time.Duration
+}
+
+// NewRetryAfter returns a new instance of RetryAfter
+func NewRetryAfter(dur time.Duration) *RetryAfter {
+	return &RetryAfter{Duration: dur}
+}
+
+func (a *RetryAfter) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	res.Before(func(w ResponseWriter) {
+		status := w.Status()
+		if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+			return
+		}
+		if w.Header().Get("Retry-After") == "" {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64((a.Duration+time.Second-1)/time.Second), 10))
+		}
+	})
+	next(res, r)
+}
@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	for status, expected := range map[int]string{
+		http.StatusServiceUnavailable:  "3",
+		http.StatusTooManyRequests:     "3",
+		http.StatusOK:                  "",
+		http.StatusInternalServerError: "",
+	} {
+		n := New(NewRetryAfter(2500 * time.Millisecond))
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(status)
+		})
+
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		n.ServeHTTP(recorder, req)
+
+		expect(t, recorder.Code, status)
+		expect(t, recorder.Header().Get("Retry-After"), expected)
+	}
+}
+
+func TestRetryAfter_existingHeader(t *testing.T) {
+	n := New(NewRetryAfter(time.Minute))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Retry-After", "120")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Header().Get("Retry-After"), "120")
+}
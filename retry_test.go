@@ -0,0 +1,86 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	n := New(NewRetry(3, nil))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			rw.Header().Set("X-Failed", "true")
+			http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		rw.Header().Set("X-Attempt", "3")
+		rw.Write([]byte("ok"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, calls, 3)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "ok")
+	expect(t, recorder.Header().Get("X-Attempt"), "3")
+	expect(t, recorder.Header().Get("X-Failed"), "")
+}
+
+func TestRetry_exhausted(t *testing.T) {
+	calls := 0
+	n := New(NewRetry(2, func(status int) bool {
+		return status == http.StatusTeapot
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("HEAD", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, calls, 3)
+	expect(t, recorder.Code, http.StatusTeapot)
+}
+
+func TestRetry_nonIdempotent(t *testing.T) {
+	calls := 0
+	n := New(NewRetry(3, nil))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, calls, 1)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+}
+
+func TestRetry_streamed(t *testing.T) {
+	calls := 0
+	n := New(NewRetry(3, nil))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte("partial"))
+		rw.(http.Flusher).Flush()
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, calls, 1)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+	expect(t, recorder.Body.String(), "partial")
+	expect(t, recorder.Flushed, true)
+}
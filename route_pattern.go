@@ -0,0 +1,11 @@
+//go:build go1.23
+// +build go1.23
+
+package negroni
+
+import "net/http"
+
+// routePattern returns the http.ServeMux pattern r matched, if any.
+func routePattern(r *http.Request) string {
+	return r.Pattern
+}
@@ -0,0 +1,12 @@
+//go:build !go1.23
+// +build !go1.23
+
+package negroni
+
+import "net/http"
+
+// routePattern returns the http.ServeMux pattern r matched, which is only
+// recorded since Go 1.23.
+func routePattern(r *http.Request) string {
+	return ""
+}
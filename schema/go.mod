@@ -0,0 +1,13 @@
+module github.com/urfave/negroni/schema
+
+go 1.19
+
+require (
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	github.com/urfave/negroni v1.0.1-0.00010101000000-000000000000
+)
+
+require golang.org/x/sync v0.1.0 // indirect
+
+// no negroni release has ProblemJSON yet, so the parent module is used
+replace github.com/urfave/negroni => ../
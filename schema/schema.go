@@ -0,0 +1,129 @@
+// Package schema validates JSON request bodies against a JSON Schema before
+// they reach the rest of a Negroni chain. It is a separate module to keep the
+// schema library out of Negroni itself.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/urfave/negroni"
+)
+
+// JSONSchema is a middleware handler that validates request bodies with a JSON
+// content type, application/json or any +json type, against a schema. Invalid
+// documents get a 422 problem details response listing the validation errors,
+// malformed JSON gets a 400 and bodies larger than MaxBodySize get a 413.
+// Requests with other content types are passed through unchecked. The body is
+// buffered, so it is still readable by the next handler.
+type JSONSchema struct {
+	MaxBodySize int64
+
+	schema *jsonschema.Schema
+}
+
+// NewJSONSchema returns a new instance of JSONSchema validating with the given
+// schema document and accepting bodies up to 1MB, or an error if the schema
+// does not compile.
+func NewJSONSchema(schema []byte) (*JSONSchema, error) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+	s, err := c.Compile("schema.json")
+	if err != nil {
+		return nil, err
+	}
+	return &JSONSchema{MaxBodySize: 1 << 20, schema: s}, nil
+}
+
+// ValidationError is a schema violation reported in the errors member of the
+// 422 responses of JSONSchema.
+type ValidationError struct {
+	// InstanceLocation is the JSON pointer of the invalid value in the body.
+	InstanceLocation string `json:"instanceLocation"`
+	Error            string `json:"error"`
+}
+
+type problem struct {
+	Type   string            `json:"type"`
+	Title  string            `json:"title"`
+	Status int               `json:"status"`
+	Errors []ValidationError `json:"errors"`
+}
+
+func (s *JSONSchema) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Body == nil || !isJSON(r.Header.Get("Content-Type")) {
+		next(rw, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, s.MaxBodySize+1))
+	r.Body.Close()
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > s.MaxBodySize {
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// the schema library expects numbers as json.Number
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		negroni.ProblemJSON(rw, http.StatusBadRequest, "", "request body is not valid JSON")
+		return
+	}
+
+	if err := s.schema.Validate(doc); err != nil {
+		ve, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		writeErrors(rw, ve)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	next(rw, r)
+}
+
+func writeErrors(rw http.ResponseWriter, ve *jsonschema.ValidationError) {
+	p := problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+	}
+	for _, e := range ve.BasicOutput().Errors {
+		// intermediate units only say which subschema failed
+		if len(e.Error) > 0 && !strings.HasPrefix(e.Error, "doesn't validate with") {
+			p.Errors = append(p.Errors, ValidationError{InstanceLocation: e.InstanceLocation, Error: e.Error})
+		}
+	}
+	body, _ := json.Marshal(p)
+
+	rw.Header().Set("Content-Type", negroni.ProblemContentType)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(http.StatusUnprocessableEntity)
+	rw.Write(body)
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/urfave/negroni"
+)
+
+const userSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["name"]
+}`
+
+func newServer(t *testing.T) (*negroni.Negroni, *string) {
+	s, err := NewJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received string
+	n := negroni.New(s)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+	})
+	return n, &received
+}
+
+func TestJSONSchema_valid(t *testing.T) {
+	n, received := newServer(t)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/users", strings.NewReader(`{"name":"gopher","age":12}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	n.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if *received != `{"name":"gopher","age":12}` {
+		t.Errorf("unexpected body downstream: %q", *received)
+	}
+}
+
+func TestJSONSchema_invalid(t *testing.T) {
+	n, received := newServer(t)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/users", strings.NewReader(`{"age":"twelve"}`))
+	req.Header.Set("Content-Type", "application/json")
+	n.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, recorder.Code)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != negroni.ProblemContentType {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	body := recorder.Body.String()
+	for _, detail := range []string{
+		`"instanceLocation":"","error":"missing properties: 'name'"`,
+		`"instanceLocation":"/age","error":"expected integer, but got string"`,
+	} {
+		if !strings.Contains(body, detail) {
+			t.Errorf("expected %s in %s", detail, body)
+		}
+	}
+	if *received != "" {
+		t.Errorf("next handler should not be called")
+	}
+}
+
+func TestJSONSchema_malformed(t *testing.T) {
+	n, _ := newServer(t)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/users", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", "application/json")
+	n.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestJSONSchema_otherContentType(t *testing.T) {
+	n, received := newServer(t)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "http://localhost:3000/users", strings.NewReader(`name=gopher`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	n.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if *received != "name=gopher" {
+		t.Errorf("unexpected body downstream: %q", *received)
+	}
+}
+
+func TestNewJSONSchema_invalidSchema(t *testing.T) {
+	if _, err := NewJSONSchema([]byte(`{"type": 12}`)); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
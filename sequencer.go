@@ -0,0 +1,147 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sequencer is a middleware handler that processes the requests of each key,
+// as returned by KeyFunc, in the order of the sequence number in their Header,
+// starting at 1. A request waits for the one before it in the sequence to
+// complete, for at most Timeout, and gets a 409 if it does not arrive in time,
+// having been skipped, or if its number was already used. Requests without a
+// valid number get a 400, and requests whose key is empty are not sequenced.
+//
+// Keys are forgotten once idle for Expiry, after which their sequence starts
+// over at 1.
+type Sequencer struct {
+	KeyFunc func(*http.Request) string
+	Header  string
+	Timeout time.Duration
+	Expiry  time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*sequence
+	nextSweep time.Time
+}
+
+type sequence struct {
+	// done is the last completed number
+	done uint64
+	// pending holds the numbers waiting or being served
+	pending map[uint64]bool
+	// changed is closed and replaced whenever done changes
+	changed  chan struct{}
+	lastUsed time.Time
+}
+
+// NewSequencer returns a new instance of Sequencer reading the X-Seq header,
+// waiting 10 seconds for missing requests and forgetting keys after 10
+// minutes.
+func NewSequencer(keyFunc func(*http.Request) string) *Sequencer {
+	return &Sequencer{
+		KeyFunc: keyFunc,
+		Header:  "X-Seq",
+		Timeout: 10 * time.Second,
+		Expiry:  10 * time.Minute,
+	}
+}
+
+func (s *Sequencer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := s.KeyFunc(r)
+	if key == "" {
+		next(rw, r)
+		return
+	}
+	n, err := strconv.ParseUint(r.Header.Get(s.Header), 10, 64)
+	if err != nil || n == 0 {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	seq, ok := s.acquire(key, n)
+	if !ok {
+		http.Error(rw, http.StatusText(http.StatusConflict), http.StatusConflict)
+		return
+	}
+	if !s.wait(r, seq, n) {
+		s.release(seq, n, false)
+		http.Error(rw, http.StatusText(http.StatusConflict), http.StatusConflict)
+		return
+	}
+	// successors must not wait forever on a panicking request
+	defer s.release(seq, n, true)
+	next(rw, r)
+}
+
+// acquire registers n as pending for key, unless it was already used.
+func (s *Sequencer) acquire(key string, n uint64) (*sequence, bool) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		s.keys = make(map[string]*sequence)
+	}
+	if !now.Before(s.nextSweep) {
+		for k, seq := range s.keys {
+			if len(seq.pending) == 0 && now.Sub(seq.lastUsed) >= s.Expiry {
+				delete(s.keys, k)
+			}
+		}
+		s.nextSweep = now.Add(s.Expiry)
+	}
+
+	seq, ok := s.keys[key]
+	if !ok {
+		seq = &sequence{pending: make(map[uint64]bool), changed: make(chan struct{})}
+		s.keys[key] = seq
+	}
+	seq.lastUsed = now
+	if n <= seq.done || seq.pending[n] {
+		return nil, false
+	}
+	seq.pending[n] = true
+	return seq, true
+}
+
+// wait blocks until n is the next number of seq, reporting false if it gave
+// up first.
+func (s *Sequencer) wait(r *http.Request, seq *sequence, n uint64) bool {
+	timer := time.NewTimer(s.Timeout)
+	defer timer.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for seq.done+1 != n {
+		changed := seq.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-timer.C:
+			s.mu.Lock()
+			return false
+		case <-r.Context().Done():
+			s.mu.Lock()
+			return false
+		}
+		s.mu.Lock()
+	}
+	return true
+}
+
+// release removes n from the pending numbers of seq, marking it done if it
+// was served.
+func (s *Sequencer) release(seq *sequence, n uint64, served bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(seq.pending, n)
+	seq.lastUsed = time.Now()
+	if served {
+		seq.done = n
+		close(seq.changed)
+		seq.changed = make(chan struct{})
+	}
+}
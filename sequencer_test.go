@@ -0,0 +1,105 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newSequencedRequest(seq int) *http.Request {
+	req, _ := http.NewRequest("POST", "http://localhost:3000/", nil)
+	req.Header.Set("X-Session", "abc")
+	req.Header.Set("X-Seq", strconv.Itoa(seq))
+	return req
+}
+
+// waitPending returns once request n of key is waiting in s.
+func waitPending(s *Sequencer, key string, n uint64) {
+	for {
+		s.mu.Lock()
+		seq := s.keys[key]
+		pending := seq != nil && seq.pending[n]
+		s.mu.Unlock()
+		if pending {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestSequencer(t *testing.T) {
+	var mu sync.Mutex
+	var order []int
+	s := NewSequencer(func(r *http.Request) string {
+		return r.Header.Get("X-Session")
+	})
+	n := New(s)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		seq, _ := strconv.Atoi(r.Header.Get("X-Seq"))
+		mu.Lock()
+		order = append(order, seq)
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 4)
+	for _, seq := range []int{3, 2, 1} {
+		wg.Add(1)
+		go func(seq int) {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			n.ServeHTTP(recorder, newSequencedRequest(seq))
+			codes[seq] = recorder.Code
+		}(seq)
+		// make the requests arrive in reverse order
+		if seq > 1 {
+			waitPending(s, "abc", uint64(seq))
+		}
+	}
+	wg.Wait()
+
+	expect(t, len(order), 3)
+	expect(t, order[0], 1)
+	expect(t, order[1], 2)
+	expect(t, order[2], 3)
+	expect(t, codes[1], http.StatusOK)
+	expect(t, codes[2], http.StatusOK)
+	expect(t, codes[3], http.StatusOK)
+}
+
+func TestSequencer_outOfOrder(t *testing.T) {
+	s := NewSequencer(func(r *http.Request) string {
+		return r.Header.Get("X-Session")
+	})
+	s.Timeout = 20 * time.Millisecond
+	n := New(s)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, newSequencedRequest(1))
+	expect(t, recorder.Code, http.StatusOK)
+
+	// duplicated
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, newSequencedRequest(1))
+	expect(t, recorder.Code, http.StatusConflict)
+
+	// skipped
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, newSequencedRequest(3))
+	expect(t, recorder.Code, http.StatusConflict)
+
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, newSequencedRequest(2))
+	expect(t, recorder.Code, http.StatusOK)
+
+	recorder = httptest.NewRecorder()
+	req := newSequencedRequest(3)
+	req.Header.Set("X-Seq", "three")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
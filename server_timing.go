@@ -0,0 +1,85 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var serverTimingKey = &contextKey{"server-timing"}
+
+// ServerTiming is a middleware handler that reports durations in the
+// Server-Timing response header. Handlers further down the chain contribute
+// entries with AddServerTiming, and a "total" entry measuring the time from
+// this middleware to the response header being written is always appended.
+type ServerTiming struct {
+	clock clock
+}
+
+// NewServerTiming returns a new instance of ServerTiming
+func NewServerTiming() *ServerTiming {
+	return &ServerTiming{}
+}
+
+// SetNow sets the function used to measure the total duration, time.Now by
+// default.
+func (s *ServerTiming) SetNow(now func() time.Time) {
+	s.clock = now
+}
+
+func (s *ServerTiming) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+
+	start := s.clock.now()
+	timings := &serverTimings{}
+	res.Before(func(ResponseWriter) {
+		timings.add("total", s.clock.since(start))
+		res.Header().Add("Server-Timing", timings.String())
+	})
+	next(res, r.WithContext(context.WithValue(r.Context(), serverTimingKey, timings)))
+}
+
+// AddServerTiming records an entry to report in the Server-Timing header of
+// the request the context belongs to. It does nothing if the request is not
+// served by ServerTiming or the header has already been written. It is safe
+// for concurrent use.
+func AddServerTiming(ctx context.Context, name string, dur time.Duration) {
+	if timings, ok := ctx.Value(serverTimingKey).(*serverTimings); ok {
+		timings.add(name, dur)
+	}
+}
+
+type serverTiming struct {
+	name string
+	dur  time.Duration
+}
+
+type serverTimings struct {
+	mu      sync.Mutex
+	entries []serverTiming
+}
+
+func (s *serverTimings) add(name string, dur time.Duration) {
+	s.mu.Lock()
+	s.entries = append(s.entries, serverTiming{name: name, dur: dur})
+	s.mu.Unlock()
+}
+
+// String formats the entries as a Server-Timing header value.
+func (s *serverTimings) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := make([]string, len(s.entries))
+	for i, e := range s.entries {
+		ms := float64(e.dur) / float64(time.Millisecond)
+		parts[i] = e.name + ";dur=" + strconv.FormatFloat(ms, 'f', 3, 64)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,56 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestServerTiming(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewServerTiming())
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		AddServerTiming(r.Context(), "db", 12500*time.Microsecond)
+		next(rw, r)
+	})
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("OK"))
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	header := recorder.Header().Get("Server-Timing")
+	if !regexp.MustCompile(`^db;dur=12\.500, total;dur=\d+\.\d{3}$`).MatchString(header) {
+		t.Errorf("Unexpected Server-Timing header %q", header)
+	}
+}
+
+func TestAddServerTiming_noMiddleware(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	// must not panic
+	AddServerTiming(req.Context(), "db", time.Second)
+}
+
+func TestServerTiming_SetNow(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewServerTiming()
+	s.SetNow(func() time.Time {
+		now = now.Add(1500 * time.Microsecond)
+		return now
+	})
+
+	recorder := httptest.NewRecorder()
+	n := New(s)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Header().Get("Server-Timing"), "total;dur=1.500")
+}
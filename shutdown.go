@@ -0,0 +1,121 @@
+package negroni
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long RunWithContext waits for in-flight
+// requests and shutdown hooks to finish.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// OnShutdown registers fn to be called when a server started by
+// RunWithContext shuts down. Hooks run after the server stopped serving, in
+// the reverse order they were registered, and all run even if some fail.
+func (n *Negroni) OnShutdown(fn func(context.Context) error) {
+	n.hooksMu.Lock()
+	n.shutdownHooks = append(n.shutdownHooks, fn)
+	n.hooksMu.Unlock()
+}
+
+// RunWithContext is like Run, but shuts the server down gracefully when ctx
+// is done instead of running forever. In-flight requests are given
+// DefaultShutdownTimeout to complete, then the OnShutdown hooks are called.
+// The errors of the shutdown and of the hooks are returned together.
+func (n *Negroni) RunWithContext(ctx context.Context, addr ...string) error {
+	l := log.New(os.Stdout, "[negroni] ", 0)
+	server := &http.Server{Addr: detectAddress(addr...), Handler: n}
+	return n.serveUntilDone(ctx, server, l)
+}
+
+// RunGraceful is like Run, but shuts the server down gracefully on SIGINT or
+// SIGTERM, as RunWithContext does when its context is done. It returns once
+// the shutdown completed; errors are logged.
+func (n *Negroni) RunGraceful(addr ...string) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	l := log.New(os.Stdout, "[negroni] ", 0)
+	server := &http.Server{Addr: detectAddress(addr...), Handler: n}
+	n.runGraceful(server, l, sigc)
+}
+
+func (n *Negroni) runGraceful(server *http.Server, l ALogger, sigc <-chan os.Signal) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-sigc:
+			l.Printf("received %s", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := n.serveUntilDone(ctx, server, l); err != nil {
+		l.Printf("%s", err)
+	}
+}
+
+func (n *Negroni) serveUntilDone(ctx context.Context, server *http.Server, l ALogger) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+	l.Printf("listening on %s", server.Addr)
+
+	select {
+	case err := <-errc:
+		// the server could not start, there is nothing to shut down
+		return err
+	case <-ctx.Done():
+	}
+
+	l.Printf("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+
+	var errs shutdownErrors
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, n.runShutdownHooks(shutdownCtx)...)
+	l.Printf("shutdown complete")
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (n *Negroni) runShutdownHooks(ctx context.Context) shutdownErrors {
+	n.hooksMu.Lock()
+	hooks := make([]func(context.Context) error, len(n.shutdownHooks))
+	copy(hooks, n.shutdownHooks)
+	n.hooksMu.Unlock()
+
+	var errs shutdownErrors
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// shutdownErrors collects the errors occurring during a shutdown.
+type shutdownErrors []error
+
+func (e shutdownErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
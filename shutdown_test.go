@@ -0,0 +1,136 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNegroniRunWithContext(t *testing.T) {
+	result := ""
+
+	n := New()
+	n.OnShutdown(func(ctx context.Context) error {
+		result += "first"
+		return errors.New("first failed")
+	})
+	n.OnShutdown(func(ctx context.Context) error {
+		result += "second"
+		return nil
+	})
+	n.OnShutdown(func(ctx context.Context) error {
+		result += "third"
+		return errors.New("third failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- n.RunWithContext(ctx, "127.0.0.1:0")
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errc:
+		refute(t, err, nil)
+		expect(t, err.Error(), "third failed; first failed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected RunWithContext to return after the context was cancelled")
+	}
+	expect(t, result, "thirdsecondfirst")
+}
+
+func TestNegroniRunWithContext_listenError(t *testing.T) {
+	called := false
+
+	n := New()
+	n.OnShutdown(func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	err := n.RunWithContext(context.Background(), "invalid address")
+	refute(t, err, nil)
+	expect(t, strings.Contains(err.Error(), "invalid address"), true)
+	expect(t, called, false)
+}
+
+func TestNegroniRunGraceful(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	entered := make(chan struct{})
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(entered)
+		time.Sleep(100 * time.Millisecond)
+		rw.Write([]byte("done"))
+	})
+
+	buff := bytes.NewBufferString("")
+	server := &http.Server{Addr: addr, Handler: n}
+	sigc := make(chan os.Signal, 1)
+	returned := make(chan struct{})
+	go func() {
+		n.runGraceful(server, log.New(buff, "[negroni] ", 0), sigc)
+		close(returned)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	resc := make(chan result, 1)
+	go func() {
+		var res *http.Response
+		var err error
+		// the server may not be listening yet
+		for i := 0; i < 50; i++ {
+			if res, err = http.Get("http://" + addr + "/"); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			resc <- result{err: err}
+			return
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		resc <- result{body: string(body), err: err}
+	}()
+
+	select {
+	case <-entered:
+	case res := <-resc:
+		t.Fatalf("Expected the request to be in flight, got %v", res.err)
+	}
+	sigc <- syscall.SIGTERM
+
+	res := <-resc
+	expect(t, res.err, nil)
+	expect(t, res.body, "done")
+
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected runGraceful to return after the signal")
+	}
+	expect(t, strings.Contains(buff.String(), "[negroni] received terminated"), true)
+	expect(t, strings.Contains(buff.String(), "[negroni] shutdown complete"), true)
+}
@@ -0,0 +1,42 @@
+package negroni
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleFlight is a middleware handler that coalesces concurrent identical GET
+// requests. Requests for which KeyFunc returns the same key while one of them
+// is being served share a single execution of the rest of the chain: the
+// response of the first request is buffered and replayed to all of them.
+// Other methods and requests with an empty key bypass the coalescing.
+type SingleFlight struct {
+	KeyFunc func(*http.Request) string
+
+	group singleflight.Group
+}
+
+// NewSingleFlight returns a new instance of SingleFlight
+func NewSingleFlight(keyFunc func(*http.Request) string) *SingleFlight {
+	return &SingleFlight{KeyFunc: keyFunc}
+}
+
+func (s *SingleFlight) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method != "GET" {
+		next(rw, r)
+		return
+	}
+	key := s.KeyFunc(r)
+	if key == "" {
+		next(rw, r)
+		return
+	}
+
+	v, _, _ := s.group.Do(key, func() (interface{}, error) {
+		buf := newResponseBuffer()
+		next(NewResponseWriter(buf), r)
+		return buf, nil
+	})
+	v.(*responseBuffer).writeTo(rw)
+}
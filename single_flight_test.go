@@ -0,0 +1,90 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flightFollowers returns how many goroutines wait in singleflight.Group.Do
+// for the flight of another one to complete. Those have joined the flight, as
+// they only wait once registered.
+func flightFollowers() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	followers := 0
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(g, "sync.(*WaitGroup).Wait(") && strings.Contains(g, "singleflight.(*Group).Do(") &&
+			!strings.Contains(g, "singleflight.(*Group).doCall(") {
+			followers++
+		}
+	}
+	return followers
+}
+
+func TestSingleFlight(t *testing.T) {
+	const requests = 10
+	var calls int32
+
+	n := New()
+	n.Use(NewSingleFlight(func(r *http.Request) string {
+		return r.URL.String()
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// complete the flight once every other request joined it, giving up
+		// eventually if some started their own flight
+		deadline := time.Now().Add(5 * time.Second)
+		for flightFollowers() < requests-1 && time.Now().Before(deadline) {
+			// dumping the stacks stops the world, so poll gently
+			time.Sleep(time.Millisecond)
+		}
+		rw.Header().Set("X-Shared", "yes")
+		rw.WriteHeader(http.StatusAccepted)
+		rw.Write([]byte("expensive"))
+	})
+
+	recorders := make([]*httptest.ResponseRecorder, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(recorder *httptest.ResponseRecorder) {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "http://localhost:3000/report", nil)
+			n.ServeHTTP(recorder, req)
+		}(recorders[i])
+	}
+
+	wg.Wait()
+
+	expect(t, atomic.LoadInt32(&calls), int32(1))
+	for _, recorder := range recorders {
+		expect(t, recorder.Code, http.StatusAccepted)
+		expect(t, recorder.Header().Get("X-Shared"), "yes")
+		expect(t, recorder.Body.String(), "expensive")
+	}
+}
+
+func TestSingleFlight_bypass(t *testing.T) {
+	calls := 0
+
+	n := New()
+	n.Use(NewSingleFlight(func(r *http.Request) string {
+		return r.URL.Query().Get("key")
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	req, _ := http.NewRequest("POST", "http://localhost:3000/?key=a", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, calls, 2)
+}
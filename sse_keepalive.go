@@ -0,0 +1,140 @@
+package negroni
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEKeepAlive is a middleware handler that keeps Server-Sent Events streams
+// alive through proxies closing idle connections. For responses with a
+// text/event-stream Content-Type, a ":keepalive" comment is written and
+// flushed whenever the handler has written nothing for Interval, until the
+// rest of the chain returns or the client goes away. Keepalives are only
+// written between events, that is when nothing was written yet or the last
+// write ended with a blank line, so an event written in several parts is
+// never split.
+type SSEKeepAlive struct {
+	Interval time.Duration
+}
+
+// NewSSEKeepAlive returns a new instance of SSEKeepAlive
+func NewSSEKeepAlive(interval time.Duration) *SSEKeepAlive {
+	return &SSEKeepAlive{Interval: interval}
+}
+
+func (s *SSEKeepAlive) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	sw := &sseResponseWriter{
+		ResponseWriter: res,
+		interval:       s.Interval,
+		clientGone:     r.Context().Done(),
+		done:           make(chan struct{}),
+	}
+	next(sw, r)
+
+	// no keepalive may be written once the handler is done with the response
+	close(sw.done)
+	sw.wg.Wait()
+}
+
+type sseResponseWriter struct {
+	ResponseWriter
+	interval   time.Duration
+	clientGone <-chan struct{}
+	done       chan struct{}
+
+	mu sync.Mutex
+	wg sync.WaitGroup
+	// written under mu, when the handler last wrote and whether that ended
+	// an event
+	lastWrite time.Time
+	midEvent  bool
+}
+
+func (sw *sseResponseWriter) WriteHeader(code int) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	sw.writeHeader(code)
+}
+
+func (sw *sseResponseWriter) writeHeader(code int) {
+	if sw.Written() {
+		sw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	sw.ResponseWriter.WriteHeader(code)
+	if sw.Written() && sw.interval > 0 &&
+		strings.HasPrefix(sw.Header().Get("Content-Type"), "text/event-stream") {
+		sw.lastWrite = time.Now()
+		sw.wg.Add(1)
+		go sw.keepAlive()
+	}
+}
+
+func (sw *sseResponseWriter) Write(b []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if !sw.Written() {
+		sw.writeHeader(http.StatusOK)
+	}
+	if len(b) > 0 {
+		sw.lastWrite = time.Now()
+		sw.midEvent = !endsEvent(b)
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// endsEvent reports whether b ends with the blank line terminating an event.
+func endsEvent(b []byte) bool {
+	s := string(b)
+	return strings.HasSuffix(s, "\n\n") || strings.HasSuffix(s, "\r\r") ||
+		strings.HasSuffix(s, "\r\n\r\n")
+}
+
+func (sw *sseResponseWriter) Flush() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if !sw.Written() {
+		sw.writeHeader(http.StatusOK)
+	}
+	sw.ResponseWriter.Flush()
+}
+
+func (sw *sseResponseWriter) keepAlive() {
+	defer sw.wg.Done()
+	timer := time.NewTimer(sw.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			sw.mu.Lock()
+			var err error
+			if !sw.midEvent && time.Since(sw.lastWrite) >= sw.interval {
+				_, err = sw.ResponseWriter.Write([]byte(":keepalive\n\n"))
+				if err == nil {
+					sw.ResponseWriter.Flush()
+				}
+				sw.lastWrite = time.Now()
+			}
+			// wait for the handler to be idle for a whole interval again
+			wait := sw.interval - time.Since(sw.lastWrite)
+			sw.mu.Unlock()
+			if err != nil {
+				return
+			}
+			if wait <= 0 {
+				wait = sw.interval
+			}
+			timer.Reset(wait)
+		case <-sw.done:
+			return
+		case <-sw.clientGone:
+			return
+		}
+	}
+}
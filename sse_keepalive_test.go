@@ -0,0 +1,105 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEKeepAlive(t *testing.T) {
+	n := New(NewSSEKeepAlive(5 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.Write([]byte("data: hello\n\n"))
+		rw.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		rw.Write([]byte("data: bye\n\n"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/events", nil)
+	n.ServeHTTP(recorder, req)
+
+	body := recorder.Body.String()
+	expect(t, strings.HasPrefix(body, "data: hello\n\n:keepalive\n\n"), true)
+	expect(t, strings.HasSuffix(body, ":keepalive\n\ndata: bye\n\n"), true)
+	expect(t, recorder.Flushed, true)
+
+	// keepalives stop with the handler
+	time.Sleep(20 * time.Millisecond)
+	expect(t, recorder.Body.String(), body)
+}
+
+func TestSSEKeepAlive_multipartEvent(t *testing.T) {
+	n := New(NewSSEKeepAlive(5 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.Write([]byte("event: update\n"))
+		rw.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		rw.Write([]byte("data: one\n"))
+		time.Sleep(30 * time.Millisecond)
+		rw.Write([]byte("data: two\n\n"))
+		time.Sleep(30 * time.Millisecond)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/events", nil)
+	n.ServeHTTP(recorder, req)
+
+	body := recorder.Body.String()
+	expect(t, strings.HasPrefix(body, "event: update\ndata: one\ndata: two\n\n:keepalive\n\n"), true)
+}
+
+func TestSSEKeepAlive_busy(t *testing.T) {
+	n := New(NewSSEKeepAlive(50 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 8; i++ {
+			rw.Write([]byte("data: tick\n\n"))
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/events", nil)
+	n.ServeHTTP(recorder, req)
+
+	// the handler never stays idle for an interval
+	expect(t, strings.Contains(recorder.Body.String(), ":keepalive"), false)
+}
+
+func TestSSEKeepAlive_notEventStream(t *testing.T) {
+	n := New(NewSSEKeepAlive(5 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain")
+		rw.Write([]byte("hello"))
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+
+	expect(t, recorder.Body.String(), "hello")
+}
+
+func TestSSEKeepAlive_clientGone(t *testing.T) {
+	n := New(NewSSEKeepAlive(5 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.WriteHeader(http.StatusOK)
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/events", nil)
+	n.ServeHTTP(recorder, req.WithContext(ctx))
+
+	expect(t, recorder.Body.String(), "")
+}
@@ -1,7 +1,10 @@
 package negroni
 
 import (
+	"io"
+	"mime"
 	"net/http"
+	"os"
 	"path"
 	"strings"
 )
@@ -11,6 +14,9 @@ import (
 // passes along to the next middleware in the chain. If you desire "fileserver"
 // type behavior where it returns a 404 for unfound files, you should consider
 // using http.FileServer from the Go stdlib.
+// Files are served with http.ServeContent, which handles Range requests
+// (answering 206 with Content-Range), If-Range and the other conditional
+// request headers.
 type Static struct {
 	// Dir is the directory to serve static files from
 	Dir http.FileSystem
@@ -29,6 +35,30 @@ func NewStatic(directory http.FileSystem) *Static {
 	}
 }
 
+// NewStaticRoots returns a new instance of Static serving files from the
+// first of the given roots that contains them. See MultiFileSystem.
+func NewStaticRoots(roots ...http.FileSystem) *Static {
+	return NewStatic(MultiFileSystem(roots))
+}
+
+// MultiFileSystem is an http.FileSystem that is the layered combination of
+// the given filesystems. Open returns the file from the first filesystem
+// that contains the name, so earlier filesystems override later ones.
+type MultiFileSystem []http.FileSystem
+
+// Open implements http.FileSystem
+func (m MultiFileSystem) Open(name string) (http.File, error) {
+	err := os.ErrNotExist
+	for _, fs := range m {
+		f, openErr := fs.Open(name)
+		if openErr == nil {
+			return f, nil
+		}
+		err = openErr
+	}
+	return nil, err
+}
+
 func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	if r.Method != "GET" && r.Method != "HEAD" {
 		next(rw, r)
@@ -84,5 +114,26 @@ func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.Ha
 		}
 	}
 
+	sniffContentType(rw, file, f)
 	http.ServeContent(rw, r, file, fi.ModTime(), f)
 }
+
+// sniffContentType sets the Content-Type from the first 512 bytes of f when
+// the extension of name is mapped to application/octet-stream, such as .bin.
+// Files without a known extension are already sniffed by http.ServeContent.
+func sniffContentType(rw http.ResponseWriter, name string, f http.File) {
+	if rw.Header().Get("Content-Type") != "" {
+		return
+	}
+	if mime.TypeByExtension(path.Ext(name)) != "application/octet-stream" {
+		return
+	}
+
+	var buf [512]byte
+	n, _ := io.ReadFull(f, buf[:])
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		// leave it to http.ServeContent
+		return
+	}
+	rw.Header().Set("Content-Type", http.DetectContentType(buf[:n]))
+}
@@ -2,8 +2,12 @@ package negroni
 
 import (
 	"bytes"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -111,3 +115,112 @@ func TestStaticOptionsPrefix(t *testing.T) {
 	n.ServeHTTP(response, req)
 	expect(t, response.Code, http.StatusOK)
 }
+
+func TestStaticRoots(t *testing.T) {
+	base, err := ioutil.TempDir("", "negroni-base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+	override, err := ioutil.TempDir("", "negroni-override")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(override)
+
+	ioutil.WriteFile(filepath.Join(base, "app.css"), []byte("base"), 0644)
+	ioutil.WriteFile(filepath.Join(base, "base.css"), []byte("base only"), 0644)
+	ioutil.WriteFile(filepath.Join(override, "app.css"), []byte("override"), 0644)
+
+	n := New()
+	n.Use(NewStaticRoots(http.Dir(override), http.Dir(base)))
+	n.UseHandler(http.NotFoundHandler())
+
+	for path, body := range map[string]string{
+		"/app.css":  "override",
+		"/base.css": "base only",
+	} {
+		response := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(response, req)
+		expect(t, response.Code, http.StatusOK)
+		expect(t, response.Body.String(), body)
+	}
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/missing.css", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(response, req)
+	expect(t, response.Code, http.StatusNotFound)
+}
+
+func TestStaticSniffContentType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "negroni-static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// not every system mime.types maps .bin
+	mime.AddExtensionType(".bin", "application/octet-stream")
+	ioutil.WriteFile(filepath.Join(dir, "manifest.bin"), []byte(`{"name": "negroni"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "page.bin"), []byte(`<!DOCTYPE html><html></html>`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "page.txt"), []byte(`<!DOCTYPE html><html></html>`), 0644)
+
+	n := New()
+	n.Use(NewStatic(http.Dir(dir)))
+
+	for name, ctype := range map[string]string{
+		"manifest.bin": "text/plain; charset=utf-8",
+		"page.bin":     "text/html; charset=utf-8",
+		"page.txt":     "text/plain; charset=utf-8",
+	} {
+		response := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000/"+name, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(response, req)
+		expect(t, response.Code, http.StatusOK)
+		expect(t, response.Header().Get("Content-Type"), ctype)
+	}
+}
+
+func TestStaticRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "negroni-range")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// no extension, so http.ServeContent sniffs the type before serving the range
+	ioutil.WriteFile(filepath.Join(dir, "media"), []byte("0123456789abcdef"), 0644)
+
+	n := New(NewStatic(http.Dir(dir)))
+
+	response := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/media", nil)
+	req.Header.Set("Range", "bytes=4-7")
+	n.ServeHTTP(response, req)
+	expect(t, response.Code, http.StatusPartialContent)
+	expect(t, response.Header().Get("Content-Range"), "bytes 4-7/16")
+	expect(t, response.Header().Get("Content-Length"), "4")
+	expect(t, response.Header().Get("Accept-Ranges"), "bytes")
+	expect(t, response.Body.String(), "4567")
+
+	// a stale If-Range gets the full content
+	response = httptest.NewRecorder()
+	req.Header.Set("If-Range", "Mon, 02 Jan 2006 15:04:05 GMT")
+	n.ServeHTTP(response, req)
+	expect(t, response.Code, http.StatusOK)
+	expect(t, response.Body.String(), "0123456789abcdef")
+
+	response = httptest.NewRecorder()
+	req.Header.Del("If-Range")
+	req.Header.Set("Range", "bytes=100-")
+	n.ServeHTTP(response, req)
+	expect(t, response.Code, http.StatusRequestedRangeNotSatisfiable)
+}
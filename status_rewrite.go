@@ -0,0 +1,51 @@
+package negroni
+
+import "net/http"
+
+// StatusRewrite is a middleware handler that remaps response status codes,
+// e.g. an upstream 418 to a 400, before they are written. Codes missing from
+// Mapping pass through unchanged. The ResponseWriter status reflects the
+// rewritten code.
+type StatusRewrite struct {
+	Mapping map[int]int
+}
+
+// NewStatusRewrite returns a new instance of StatusRewrite
+func NewStatusRewrite(mapping map[int]int) *StatusRewrite {
+	return &StatusRewrite{Mapping: mapping}
+}
+
+func (s *StatusRewrite) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	next(&statusRewriteWriter{ResponseWriter: res, mapping: s.Mapping}, r)
+}
+
+type statusRewriteWriter struct {
+	ResponseWriter
+	mapping map[int]int
+}
+
+func (w *statusRewriteWriter) WriteHeader(code int) {
+	if to, ok := w.mapping[code]; ok {
+		code = to
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRewriteWriter) Write(b []byte) (int, error) {
+	if !w.Written() {
+		// the implicit 200 is subject to the mapping too
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRewriteWriter) Flush() {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,49 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRewrite(t *testing.T) {
+	var status int
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+		status = rw.(ResponseWriter).Status()
+	})
+	n.Use(NewStatusRewrite(map[int]int{http.StatusTeapot: http.StatusBadRequest}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		code := http.StatusTeapot
+		if r.URL.Path == "/unmapped" {
+			code = http.StatusConflict
+		}
+		rw.WriteHeader(code)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+	expect(t, status, http.StatusBadRequest)
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://localhost:3000/unmapped", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusConflict)
+	expect(t, status, http.StatusConflict)
+}
+
+func TestStatusRewrite_implicitOK(t *testing.T) {
+	n := New(NewStatusRewrite(map[int]int{http.StatusOK: http.StatusAccepted}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("queued"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusAccepted)
+	expect(t, recorder.Body.String(), "queued")
+}
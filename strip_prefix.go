@@ -0,0 +1,62 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var originalPathKey = &contextKey{"original-path"}
+
+// StripPrefix is a middleware handler that removes Prefix from the request
+// URL's Path (and RawPath if set) before calling the next handler, like
+// http.StripPrefix. Requests whose path does not start with Prefix get a 404
+// and the next handler is not called. The path as received is kept in the
+// request context, see OriginalPath. An empty Prefix passes every request
+// through unchanged.
+type StripPrefix struct {
+	Prefix string
+}
+
+// NewStripPrefix returns a new instance of StripPrefix
+func NewStripPrefix(prefix string) *StripPrefix {
+	return &StripPrefix{Prefix: prefix}
+}
+
+func (s *StripPrefix) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if s.Prefix == "" {
+		next(rw, r)
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, s.Prefix)
+	rp := strings.TrimPrefix(r.URL.RawPath, s.Prefix)
+	if len(p) == len(r.URL.Path) || (r.URL.RawPath != "" && len(rp) == len(r.URL.RawPath)) {
+		http.NotFound(rw, r)
+		return
+	}
+
+	r2 := r.WithContext(withOriginalPath(r.Context(), r.URL.Path))
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = p
+	r2.URL.RawPath = rp
+	next(rw, r2)
+}
+
+// withOriginalPath stores path as the original request path unless an outer
+// handler already did.
+func withOriginalPath(ctx context.Context, path string) context.Context {
+	if _, ok := ctx.Value(originalPathKey).(string); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, originalPathKey, path)
+}
+
+// OriginalPath returns the request path as it was before any handler rewrote
+// it, or false if it was not rewritten.
+func OriginalPath(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(originalPathKey).(string)
+	return path, ok
+}
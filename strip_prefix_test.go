@@ -0,0 +1,61 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripPrefix(t *testing.T) {
+	var path, original string
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewStripPrefix("/app"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		original, _ = OriginalPath(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/app/users?id=1", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, path, "/users")
+	expect(t, original, "/app/users")
+	expect(t, req.URL.Path, "/app/users")
+}
+
+func TestStripPrefix_noMatch(t *testing.T) {
+	called := false
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewStripPrefix("/app"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/other/users", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNotFound)
+	expect(t, called, false)
+}
+
+func TestStripPrefix_empty(t *testing.T) {
+	var path string
+	rewritten := true
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewStripPrefix(""))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, rewritten = OriginalPath(r.Context())
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:3000/users", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, path, "/users")
+	expect(t, rewritten, false)
+}
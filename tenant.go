@@ -0,0 +1,64 @@
+package negroni
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var tenantKey = &contextKey{"tenant"}
+
+// TenantFromHost is a middleware handler for multi-tenant applications served
+// on subdomains of BaseDomain. The leftmost label of the request host, "acme"
+// in acme.app.com, is stored as the tenant in the request context, see
+// TenantFromContext. Hosts are compared case-insensitively and their port is
+// ignored. Requests for BaseDomain itself or another domain get no tenant, and
+// a 404 if Required is set.
+type TenantFromHost struct {
+	BaseDomain string
+	Required   bool
+}
+
+// NewTenantFromHost returns a new instance of TenantFromHost
+func NewTenantFromHost(baseDomain string) *TenantFromHost {
+	return &TenantFromHost{BaseDomain: baseDomain}
+}
+
+func (t *TenantFromHost) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	tenant := t.tenant(r.Host)
+	if tenant == "" {
+		if t.Required {
+			http.NotFound(rw, r)
+			return
+		}
+		next(rw, r)
+		return
+	}
+	next(rw, r.WithContext(context.WithValue(r.Context(), tenantKey, tenant)))
+}
+
+// tenant returns the leftmost label of host if it is a subdomain of
+// BaseDomain, or "".
+func (t *TenantFromHost) tenant(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	suffix := "." + strings.ToLower(strings.Trim(t.BaseDomain, "."))
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	sub := strings.TrimSuffix(host, suffix)
+	if i := strings.IndexByte(sub, '.'); i >= 0 {
+		sub = sub[:i]
+	}
+	return sub
+}
+
+// TenantFromContext returns the tenant found by TenantFromHost for the
+// request the context belongs to, or "" if there is none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
@@ -0,0 +1,55 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTenantFromHost(t *testing.T) {
+	for host, tenant := range map[string]string{
+		"acme.app.com":      "acme",
+		"ACME.App.com:8443": "acme",
+		"eu.acme.app.com":   "eu",
+		"acme.app.com.":     "acme",
+		"app.com":           "",
+		"acme.other.com":    "",
+		"acmeapp.com":       "",
+		".app.com":          "",
+	} {
+		var got string
+		called := false
+		n := New(NewTenantFromHost("app.com"))
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			called = true
+			got = TenantFromContext(r.Context())
+		})
+
+		req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+		req.Host = host
+		n.ServeHTTP(httptest.NewRecorder(), req)
+
+		expect(t, called, true)
+		expect(t, got, tenant)
+	}
+}
+
+func TestTenantFromHost_required(t *testing.T) {
+	tm := NewTenantFromHost("app.com")
+	tm.Required = true
+	n := New(tm)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(TenantFromContext(r.Context())))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://acme.app.com/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "acme")
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "http://app.com/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNotFound)
+}
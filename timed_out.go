@@ -0,0 +1,37 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+)
+
+// timeoutMarker is implemented by writers returned by NewResponseWriter, for
+// deadline middleware to tell the middleware served before them, like Logger,
+// that the request ran past its deadline.
+type timeoutMarker interface {
+	markTimedOut(handler string)
+	timedOut() (ok bool, handler string)
+}
+
+// markTimedOut flags the response as timed out if r, as served by the rest of
+// the chain, is past its deadline. The handler is named after the route
+// pattern r matched, when known. http.ServeMux records the pattern on the
+// request it is given, so it is only known when no middleware between the
+// deadline middleware and the ServeMux replaced r, with WithContext for
+// instance.
+func markTimedOut(rw http.ResponseWriter, r *http.Request) {
+	if !TimedOut(r.Context()) {
+		return
+	}
+	if m, ok := rw.(timeoutMarker); ok {
+		m.markTimedOut(routePattern(r))
+	}
+}
+
+// TimedOut reports whether the request the context belongs to ran past the
+// deadline of ctx. A request cancelled by the client did not time out. Logger
+// also flags requests that ran past a deadline applied further down the chain
+// by a middleware such as HeaderDeadline, see LoggerEntry.TimedOut.
+func TimedOut(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded
+}
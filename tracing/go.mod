@@ -0,0 +1,16 @@
+module github.com/urfave/negroni/tracing
+
+go 1.19
+
+require (
+	github.com/urfave/negroni v1.0.1-0.00010101000000-000000000000
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	go.opentelemetry.io/otel v1.16.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+)
+
+// no negroni release has TraceContext yet, so the parent module is used
+replace github.com/urfave/negroni => ../
@@ -0,0 +1,31 @@
+// Package tracing connects the Negroni Logger to OpenTelemetry, so access logs
+// carry the trace_id and span_id of the request span and can be correlated
+// with traces. It is a separate module to keep the OpenTelemetry dependency
+// out of Negroni itself.
+package tracing
+
+import (
+	"context"
+
+	"github.com/urfave/negroni"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceContext returns the trace and span IDs of the OpenTelemetry span in
+// ctx, or empty strings if there is none. It is meant for
+// negroni.Logger.TraceContext.
+func TraceContext(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}
+
+// NewLogger returns a negroni.NewLogger filling the TraceID and SpanID of its
+// entries from the OpenTelemetry span of the request.
+func NewLogger() *negroni.Logger {
+	l := negroni.NewLogger()
+	l.TraceContext = TraceContext
+	return l
+}
@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/urfave/negroni"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger(t *testing.T) {
+	var entry negroni.LoggerEntry
+	var buff bytes.Buffer
+	l := NewLogger()
+	l.ALogger = log.New(&buff, "[negroni] ", 0)
+	l.SetFormat("trace_id={{.TraceID}} span_id={{.SpanID}}")
+
+	n := negroni.New(l)
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	if got := buff.String(); got != "[negroni] trace_id= span_id=\n" {
+		t.Errorf("Expected empty IDs without a span, got %q", got)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	req = req.WithContext(trace.ContextWithSpanContext(req.Context(), sc))
+
+	l.Hook = func(e negroni.LoggerEntry) {
+		entry = e
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	if entry.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || entry.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected IDs %q %q", entry.TraceID, entry.SpanID)
+	}
+}
@@ -0,0 +1,26 @@
+package negroni
+
+import (
+	"net/http"
+)
+
+// URLLengthLimit is a middleware handler that rejects requests whose URL is
+// longer than Max bytes with a 414 (URI Too Long), without calling the next
+// handler. For requests received by a server the URL is the request target,
+// path and query.
+type URLLengthLimit struct {
+	Max int
+}
+
+// NewURLLengthLimit returns a new instance of URLLengthLimit
+func NewURLLengthLimit(max int) *URLLengthLimit {
+	return &URLLengthLimit{Max: max}
+}
+
+func (u *URLLengthLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if len(r.URL.String()) > u.Max {
+		http.Error(rw, http.StatusText(http.StatusRequestURITooLong), http.StatusRequestURITooLong)
+		return
+	}
+	next(rw, r)
+}
@@ -0,0 +1,31 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestURLLengthLimit(t *testing.T) {
+	called := false
+
+	n := New()
+	n.Use(NewURLLengthLimit(32))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/short?q=1", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, called, true)
+
+	called = false
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/long?q="+strings.Repeat("a", 32), nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusRequestURITooLong)
+	expect(t, called, false)
+}
@@ -0,0 +1,39 @@
+package negroni
+
+import "net/http"
+
+// VersionHeader is a middleware handler that adds the X-App-Version and
+// X-Build-Commit headers to every response, just before the header is
+// written. Empty values are omitted, and Disabled turns the headers off
+// altogether, e.g. in production if leaking build info is a concern.
+type VersionHeader struct {
+	Version  string
+	Commit   string
+	Disabled bool
+}
+
+// NewVersionHeader returns a new instance of VersionHeader
+func NewVersionHeader(version, commit string) *VersionHeader {
+	return &VersionHeader{Version: version, Commit: commit}
+}
+
+func (v *VersionHeader) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if v.Disabled {
+		next(rw, r)
+		return
+	}
+
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+	res.Before(func(ResponseWriter) {
+		if v.Version != "" {
+			res.Header().Set("X-App-Version", v.Version)
+		}
+		if v.Commit != "" {
+			res.Header().Set("X-Build-Commit", v.Commit)
+		}
+	})
+	next(res, r)
+}
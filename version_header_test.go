@@ -0,0 +1,27 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionHeader(t *testing.T) {
+	v := NewVersionHeader("1.2.3", "abc123")
+	n := New(v)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:3000/", nil)
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("X-App-Version"), "1.2.3")
+	expect(t, recorder.Header().Get("X-Build-Commit"), "abc123")
+
+	v.Disabled = true
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("X-App-Version"), "")
+	expect(t, recorder.Header().Get("X-Build-Commit"), "")
+}